@@ -0,0 +1,230 @@
+package glot
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// AxisName names an axis of a plot.
+type AxisName string
+
+// The axes of a plot.
+const (
+	AxisX  AxisName = "x"
+	AxisY  AxisName = "y"
+	AxisZ  AxisName = "z"
+	AxisX2 AxisName = "x2"
+	AxisY2 AxisName = "y2"
+	AxisCB AxisName = "cb" // The color box of pm3d and image plots.
+)
+
+// Axes are the pair of axes a series is drawn against.
+type Axes string
+
+// The pairs of axes. x2 is the top axis and y2 the right axis, the
+// secondary axes are configured by SetAxis like the others.
+const (
+	AxesX1Y1 Axes = "x1y1"
+	AxesX1Y2 Axes = "x1y2"
+	AxesX2Y1 Axes = "x2y1"
+	AxesX2Y2 Axes = "x2y2"
+)
+
+func (axes Axes) valid() bool {
+	switch axes {
+	case AxesX1Y1, AxesX1Y2, AxesX2Y1, AxesX2Y2:
+		return true
+	}
+	return false
+}
+
+// Autoscale is how the open ends of the range of an axis are placed.
+type Autoscale int
+
+// The autoscale modes.
+const (
+	AutoscaleExtend Autoscale = iota // Extends the range to the next major tick, the gnuplot default.
+	AutoscaleFix                     // Fits the range to the extremes of the data.
+)
+
+// Axis is the configuration of an axis. Its zero value is the gnuplot
+// default for every setting but Label and TickFormat, which are left as
+// they are when empty.
+//
+// Usage
+//
+//	plot.SetAxis(glot.AxisX, glot.Axis{
+//		Min:        glot.Bound(0.001), // Max is left open.
+//		Autoscale:  glot.AutoscaleFix,
+//		LogBase:    10,
+//		MinorTicks: 9,
+//		Grid:       true,
+//	})
+//	plot.SetAxis(glot.AxisY, glot.Axis{Min: glot.Bound(0), Max: glot.Bound(0.5), Reverse: true, TickFormat: "%.2f"})
+type Axis struct {
+	Min, Max     *float64  // Ends of the range, an end is autoscaled when nil.
+	Reverse      bool      // Whether the axis goes from Max to Min.
+	Autoscale    Autoscale // How the ends left nil are placed.
+	LogBase      float64   // Base of a logarithmic axis, 0 for a linear axis.
+	TickInterval float64   // Interval between major ticks, 0 to let gnuplot choose.
+	MinorTicks   int       // Number of minor intervals between major ticks, 0 for the gnuplot default.
+	TickFormat   string    // printf-like format of the tick labels, like "%.2f".
+	Label        string    // Label of the axis.
+	Grid         bool      // Whether grid lines are drawn at the major ticks.
+	MinorGrid    bool      // Whether grid lines are drawn at the minor ticks.
+}
+
+// Bound returns a pointer to v, to set the ends of the range of an Axis.
+func Bound(v float64) *float64 {
+	return &v
+}
+
+// SetAxis configures an axis of the plot.
+func (plot *Plot) SetAxis(name AxisName, axis Axis) error {
+	cmds, err := axis.commands(name)
+	if err != nil {
+		return err
+	}
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	if name == AxisX2 || name == AxisY2 {
+		if err := plot.enableAxis(name); err != nil {
+			return err
+		}
+	}
+	for _, c := range cmds {
+		if err := plot.applySetting(c.key, c.cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// useAxes shows the secondary axes of a series drawn against axes.
+func (plot *Plot) useAxes(axes Axes) error {
+	if axes == "" || axes == AxesX1Y1 {
+		return nil
+	}
+	if plot.dimensions == 3 {
+		return &GnuplotError{Kind: ErrDimensionMismatch, Msg: "secondary axes can't be used by 3-d plots"}
+	}
+	if axes == AxesX2Y1 || axes == AxesX2Y2 {
+		if err := plot.enableAxis(AxisX2); err != nil {
+			return err
+		}
+	}
+	if axes == AxesX1Y2 || axes == AxesX2Y2 {
+		return plot.enableAxis(AxisY2)
+	}
+	return nil
+}
+
+// enableAxis shows the ticks of the secondary axis x2 or y2, which gnuplot
+// hides by default, in place of the ticks the primary axis mirrors on the
+// same side.
+func (plot *Plot) enableAxis(name AxisName) error {
+	if plot.secondary[name] {
+		return nil
+	}
+	n := string(name)
+	primary := strings.TrimSuffix(n, "2")
+	for _, s := range []setting{
+		{n + "tics", fmt.Sprintf("set %stics", n)},
+		{primary + "tics mirror", fmt.Sprintf("set %stics nomirror", primary)},
+	} {
+		if err := plot.applySetting(s.key, s.cmd); err != nil {
+			return err
+		}
+	}
+	if plot.secondary == nil {
+		plot.secondary = make(map[AxisName]bool)
+	}
+	plot.secondary[name] = true
+	return nil
+}
+
+// commands returns the settings applying the configuration to the axis.
+func (axis Axis) commands(name AxisName) ([]setting, error) {
+	switch name {
+	case AxisX, AxisY, AxisZ, AxisX2, AxisY2, AxisCB:
+	default:
+		return nil, &GnuplotError{Msg: fmt.Sprintf("invalid axis '%s'", name)}
+	}
+	for _, v := range []*float64{axis.Min, axis.Max} {
+		if v != nil && (math.IsNaN(*v) || math.IsInf(*v, 0)) {
+			return nil, &GnuplotError{Msg: fmt.Sprintf("invalid range end '%v' for the %s axis", *v, name)}
+		}
+	}
+	switch {
+	case axis.Autoscale != AutoscaleExtend && axis.Autoscale != AutoscaleFix:
+		return nil, &GnuplotError{Msg: fmt.Sprintf("invalid autoscale mode '%v'", axis.Autoscale)}
+	case axis.LogBase != 0 && !(axis.LogBase > 1) || math.IsInf(axis.LogBase, 0):
+		return nil, &GnuplotError{Msg: fmt.Sprintf("invalid logarithm base '%v'", axis.LogBase)}
+	case axis.TickInterval < 0 || math.IsNaN(axis.TickInterval) || math.IsInf(axis.TickInterval, 0):
+		return nil, &GnuplotError{Msg: fmt.Sprintf("invalid tick interval '%v'", axis.TickInterval)}
+	case axis.MinorTicks < 0:
+		return nil, &GnuplotError{Msg: fmt.Sprintf("invalid number of minor ticks '%v'", axis.MinorTicks)}
+	}
+	n := string(name)
+	autoscale := "set autoscale " + n
+	if axis.Autoscale == AutoscaleFix {
+		autoscale += "fix"
+	}
+	min, max := rangeEnd(axis.Min), rangeEnd(axis.Max)
+	reverse := "noreverse"
+	if axis.Reverse {
+		if axis.Min != nil && axis.Max != nil {
+			// gnuplot only reverses autoscaled axes, a fixed range is
+			// reversed by swapping its ends.
+			min, max = max, min
+		} else {
+			reverse = "reverse"
+		}
+	}
+	cmds := []setting{
+		{"autoscale " + n, autoscale},
+		{n + "range", fmt.Sprintf("set %srange [%s:%s] %s", n, min, max, reverse)},
+	}
+	if axis.LogBase > 1 {
+		cmds = append(cmds, setting{"logscale " + n, fmt.Sprintf("set logscale %s %v", n, axis.LogBase)})
+	} else {
+		cmds = append(cmds, setting{"logscale " + n, "unset logscale " + n})
+	}
+	if axis.TickInterval > 0 {
+		cmds = append(cmds, setting{n + "tics", fmt.Sprintf("set %stics %v", n, axis.TickInterval)})
+	} else {
+		cmds = append(cmds, setting{n + "tics", fmt.Sprintf("set %stics autofreq", n)})
+	}
+	if axis.MinorTicks > 0 {
+		cmds = append(cmds, setting{"m" + n + "tics", fmt.Sprintf("set m%stics %d", n, axis.MinorTicks)})
+	} else {
+		cmds = append(cmds, setting{"m" + n + "tics", fmt.Sprintf("set m%stics default", n)})
+	}
+	if axis.TickFormat != "" {
+		cmds = append(cmds, setting{"format " + n, fmt.Sprintf("set format %s %s", n, Quote(axis.TickFormat))})
+	}
+	if axis.Label != "" {
+		cmds = append(cmds, setting{n + "label", fmt.Sprintf("set %slabel %s", n, Quote(axis.Label))})
+	}
+	grid := fmt.Sprintf("set grid no%stics nom%stics", n, n)
+	switch {
+	case axis.Grid && axis.MinorGrid:
+		grid = fmt.Sprintf("set grid %stics m%stics", n, n)
+	case axis.Grid:
+		grid = fmt.Sprintf("set grid %stics nom%stics", n, n)
+	case axis.MinorGrid:
+		grid = fmt.Sprintf("set grid no%stics m%stics", n, n)
+	}
+	cmds = append(cmds, setting{"grid " + n, grid})
+	return cmds, nil
+}
+
+// rangeEnd writes an end of a range, "*" when it's autoscaled.
+func rangeEnd(v *float64) string {
+	if v == nil {
+		return "*"
+	}
+	return strconv.FormatFloat(*v, 'g', -1, 64)
+}
@@ -0,0 +1,159 @@
+package glot
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSetAxis(t *testing.T) {
+	recorder := NewRecorder()
+	plot, _ := NewPlotWithBackend(2, recorder)
+	err := plot.SetAxis(AxisX, Axis{
+		Min:          Bound(0.001),
+		Autoscale:    AutoscaleFix,
+		LogBase:      10,
+		TickInterval: 0.5,
+		MinorTicks:   4,
+		TickFormat:   "%.1f",
+		Label:        "Size",
+		Grid:         true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"set autoscale xfix",
+		"set xrange [0.001:*] noreverse",
+		"set logscale x 10",
+		"set xtics 0.5",
+		"set mxtics 4",
+		`set format x "%.1f"`,
+		`set xlabel "Size"`,
+		"set grid xtics nomxtics",
+	}
+	if got := recorder.Commands(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	recorder.Reset()
+	if err := plot.SetAxis(AxisY2, Axis{Min: Bound(0), Max: Bound(0.5), Reverse: true, MinorGrid: true}); err != nil {
+		t.Fatal(err)
+	}
+	want = []string{
+		"set y2tics",
+		"set ytics nomirror",
+		"set autoscale y2",
+		"set y2range [0.5:0] noreverse",
+		"unset logscale y2",
+		"set y2tics autofreq",
+		"set my2tics default",
+		"set grid noy2tics my2tics",
+	}
+	if got := recorder.Commands(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	recorder.Reset()
+	if err := plot.SetAxis(AxisCB, Axis{Reverse: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got := recorder.Commands()[1]; got != "set cbrange [*:*] reverse" {
+		t.Errorf("Expected an autoscaled reversed range, got %q", got)
+	}
+}
+
+func TestSetAxisReplacesRange(t *testing.T) {
+	plot, _ := NewPlotWithBackend(2, NewRecorder())
+	plot.SetXrange(-2, 2)
+	plot.SetAxis(AxisX, Axis{Max: Bound(1.5)})
+	var script strings.Builder
+	if err := plot.WriteScript(&script); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(script.String(), "[-2:2]") || !strings.Contains(script.String(), "set xrange [*:1.5] noreverse") {
+		t.Errorf("Expected the range of the axis to replace the one of SetXrange, got %q", script.String())
+	}
+}
+
+func TestSetAxisErrors(t *testing.T) {
+	recorder := NewRecorder()
+	plot, _ := NewPlotWithBackend(2, recorder)
+	for name, axis := range map[AxisName]Axis{
+		"x; quit":    {},
+		AxisX:        {Min: Bound(math.NaN())},
+		AxisY:        {Max: Bound(math.Inf(1))},
+		AxisZ:        {Autoscale: Autoscale(7)},
+		AxisX2:       {LogBase: 1},
+		AxisY2:       {TickInterval: -1},
+		AxisCB:       {MinorTicks: -1},
+		AxisName(""): {},
+	} {
+		err := plot.SetAxis(name, axis)
+		var gerr *GnuplotError
+		if !errors.As(err, &gerr) {
+			t.Errorf("Expected a GnuplotError for the %q axis %+v, got %v", name, axis, err)
+		}
+	}
+	if got := recorder.Commands(); len(got) != 0 {
+		t.Errorf("Expected no command for invalid axes, got %q", got)
+	}
+}
+
+func TestSecondaryAxes(t *testing.T) {
+	recorder := NewRecorder()
+	plot, _ := NewPlotWithBackend(2, recorder)
+	plot.AddPointGroup("Latency", StyleLines, [][]float64{{1, 2, 3}, {12, 14, 11}})
+	if err := plot.AddPointGroup("Throughput", StyleLines, [][]float64{{1, 2, 3}, {900, 850, 990}}, SeriesOptions{Axes: AxesX1Y2}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`plot $data1 title "Latency" with lines`,
+		"set y2tics",
+		"set ytics nomirror",
+		`replot $data2 axes x1y2 title "Throughput" with lines`,
+	}
+	if got := recorder.Commands(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	recorder.Reset()
+	plot.SetAxis(AxisY2, Axis{Min: Bound(0), Label: "req/s"})
+	if err := plot.ResetPointGroupStyle("Latency", StylePoints, SeriesOptions{Axes: AxesX2Y1}); err != nil {
+		t.Fatal(err)
+	}
+	got := recorder.Commands()
+	if got[0] != "set autoscale y2" {
+		t.Errorf("Expected y2 to be shown once, got %q", got)
+	}
+	want = []string{
+		"set x2tics",
+		"set xtics nomirror",
+		`plot $data1 axes x2y1 title "Latency" with points`,
+		`replot $data2 axes x1y2 title "Throughput" with lines`,
+	}
+	if got := got[len(got)-4:]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	if err := plot.AddPointGroup("Errors", StyleLines, []float64{1, 2}, SeriesOptions{Axes: "x3y1"}); err == nil {
+		t.Error("Expected an error for invalid axes")
+	}
+
+	recorder = NewRecorder()
+	plot1d, _ := NewPlotWithBackend(1, recorder)
+	AddSeries(plot1d, "Latency", StyleLines, []float64{12, 14, 11})
+	if err := AddSeries(plot1d, "Throughput", StyleLines, []int{900, 850, 990}, SeriesOptions{Axes: AxesX1Y2}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := recorder.Commands()[3], `replot $data2 axes x1y2 title "Throughput" with lines`; got != want {
+		t.Errorf("Expected %q for a 1-d plot, got %q", want, got)
+	}
+	plot3d, _ := NewPlotWithBackend(3, NewRecorder())
+	err := plot3d.AddPointGroup("Surface", StylePoints, [][]float64{{1}, {2}, {3}}, SeriesOptions{Axes: AxesX1Y2})
+	if !errors.Is(err, ErrDimensionMismatch) {
+		t.Errorf("Expected ErrDimensionMismatch for secondary axes in 3-d, got %v", err)
+	}
+}
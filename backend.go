@@ -0,0 +1,162 @@
+package glot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Backend is what a Plot draws through.
+// The gnuplot process started by NewPlot is the default Backend. Recorder
+// is a Backend which only records what it's sent, so code building plots
+// can be tested without gnuplot.
+type Backend interface {
+	// Exec sends a command and returns the lines the backend printed
+	// while executing it.
+	Exec(ctx context.Context, cmd string) ([]string, error)
+	// SendData makes a set of columns available to plot commands and
+	// returns how plot commands refer to them.
+	SendData(ctx context.Context, columns [][]float64) (string, error)
+	// ReleaseData frees the data referred to by ref, which was returned
+	// by SendData.
+	ReleaseData(ctx context.Context, ref string) error
+	// Render saves the current plot in the given format to a file.
+	Render(ctx context.Context, format, filename string) error
+	// RenderTo writes the current plot in the given format to w.
+	RenderTo(ctx context.Context, format string, w io.Writer) error
+	// Close releases the resources held by the backend.
+	Close(ctx context.Context) error
+}
+
+// formatChecker is implemented by the backends which can tell in advance
+// whether they are able to render a format.
+type formatChecker interface {
+	checkFormat(format string) error
+}
+
+// plotRenderer is implemented by the backends which can save or render
+// the plot drawn by a given plot command rather than by replotting the
+// last one, so that a deferred plot is only drawn once, on the terminal
+// of the format.
+type plotRenderer interface {
+	renderPlot(ctx context.Context, format, filename, plotCmd string) error
+	renderPlotTo(ctx context.Context, format string, w io.Writer, plotCmd string) error
+}
+
+// RecordKind tells what a Record holds.
+type RecordKind int
+
+// The kinds of Record.
+const (
+	RecordCmd     RecordKind = iota // A command sent with Exec.
+	RecordData                      // Columns sent with SendData.
+	RecordRender                    // A call to Render or RenderTo.
+	RecordRelease                   // A call to ReleaseData.
+)
+
+// Record is a single call made to a Recorder.
+type Record struct {
+	Kind     RecordKind
+	Cmd      string      // The command, for RecordCmd.
+	Ref      string      // How plot commands refer to the data, for RecordData and RecordRelease.
+	Data     [][]float64 // The columns, for RecordData.
+	Format   string      // The format, for RecordRender.
+	Filename string      // The file, for RecordRender, empty for RenderTo.
+}
+
+// Recorder is a Backend which records the exact stream of commands and
+// data sent by a Plot instead of drawing anything.
+// A Recorder is safe for concurrent use by multiple goroutines.
+//
+// Usage
+//
+//	recorder := glot.NewRecorder()
+//	plot, _ := glot.NewPlotWithBackend(1, recorder)
+//	plot.AddPointGroup("Sample1", "lines", []float64{2, 3, 4, 1})
+//	plot.SetTitle("Test Results")
+//	recorder.Commands() // [plot $data1 title "Sample1" with lines, set title "Test Results" ]
+type Recorder struct {
+	mu      sync.Mutex
+	records []Record
+	ndata   int // Number of data sets sent so far.
+}
+
+// NewRecorder makes an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Exec records the command. It never prints anything.
+func (r *Recorder) Exec(ctx context.Context, cmd string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, Record{Kind: RecordCmd, Cmd: cmd})
+	return nil, nil
+}
+
+// SendData records the columns and names them $data1, $data2 and so on.
+func (r *Recorder) SendData(ctx context.Context, columns [][]float64) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ndata++
+	ref := fmt.Sprintf("$data%d", r.ndata)
+	r.records = append(r.records, Record{Kind: RecordData, Ref: ref, Data: columns})
+	return ref, nil
+}
+
+// ReleaseData records which data is released.
+func (r *Recorder) ReleaseData(ctx context.Context, ref string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, Record{Kind: RecordRelease, Ref: ref})
+	return nil
+}
+
+// Render records the format and the file name.
+func (r *Recorder) Render(ctx context.Context, format, filename string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, Record{Kind: RecordRender, Format: format, Filename: filename})
+	return nil
+}
+
+// RenderTo records the format. It writes nothing to w.
+func (r *Recorder) RenderTo(ctx context.Context, format string, w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, Record{Kind: RecordRender, Format: format})
+	return nil
+}
+
+// Close does nothing.
+func (r *Recorder) Close(ctx context.Context) error {
+	return nil
+}
+
+// Records returns everything recorded so far, in order.
+func (r *Recorder) Records() []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Record(nil), r.records...)
+}
+
+// Commands returns the commands recorded so far, in order.
+func (r *Recorder) Commands() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var cmds []string
+	for _, record := range r.records {
+		if record.Kind == RecordCmd {
+			cmds = append(cmds, record.Cmd)
+		}
+	}
+	return cmds
+}
+
+// Reset forgets everything recorded so far.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = nil
+}
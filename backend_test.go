@@ -0,0 +1,26 @@
+package glot
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	recorder := NewRecorder()
+	plot, err := NewPlotWithBackend(2, recorder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plot.AddPointGroup("Sample1", "points", [][]float64{{1, 2, 3}, {4, 1, 7}})
+	plot.SetXLabel("X-Axis")
+	plot.SavePlot("1.png")
+	want := []Record{
+		{Kind: RecordData, Ref: "$data1", Data: [][]float64{{1, 2, 3}, {4, 1, 7}}},
+		{Kind: RecordCmd, Cmd: `plot $data1 title "Sample1" with points`},
+		{Kind: RecordCmd, Cmd: `set xlabel "X-Axis"`},
+		{Kind: RecordRender, Format: "png", Filename: "1.png"},
+	}
+	if got := recorder.Records(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected records %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,121 @@
+package glot
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// GnuplotCapabilities describes the gnuplot executable used by glot.
+type GnuplotCapabilities struct {
+	Path       string   // Path of the gnuplot executable.
+	Version    string   // Version of gnuplot, for example "5.4".
+	Patchlevel string   // Patchlevel of gnuplot, for example "8".
+	Terminals  []string // Terminals gnuplot was built with.
+}
+
+// gFormatTerminals maps every supported saving format to the gnuplot
+// terminals able to produce it, in order of preference.
+var gFormatTerminals = map[string][]string{
+	"png":  {"pngcairo", "png"},
+	"pdf":  {"pdfcairo", "pdf"},
+	"svg":  {"svg"},
+	"jpeg": {"jpeg"},
+}
+
+var gCapabilities = struct {
+	sync.Mutex
+	byPath map[string]*GnuplotCapabilities
+}{byPath: make(map[string]*GnuplotCapabilities)}
+
+// Capabilities reports the version of gnuplot and the terminals it was
+// built with. gnuplot is only run the first time Capabilities is called
+// for a given gnuplot executable, later calls return the same result.
+//
+// Usage
+//
+//	caps, err := glot.Capabilities()
+//	if err != nil { /* gnuplot isn't installed */ }
+//	if caps.HasTerminal("pdfcairo") { /* pdf can be saved */ }
+func Capabilities() (*GnuplotCapabilities, error) {
+	return capabilities(gGnuplotCmd)
+}
+
+// capabilities is like Capabilities for the gnuplot executable at path.
+func capabilities(path string) (*GnuplotCapabilities, error) {
+	if path == "" {
+		return nil, &GnuplotError{Kind: ErrGnuplotNotFound, Msg: "could not find path to 'gnuplot', set a custom path with SetCustomPathToGNUPlot", Err: gGnuplotErr}
+	}
+	gCapabilities.Lock()
+	defer gCapabilities.Unlock()
+	if caps, ok := gCapabilities.byPath[path]; ok {
+		return caps, nil
+	}
+	caps, err := probeCapabilities(path)
+	if err != nil {
+		return nil, err
+	}
+	gCapabilities.byPath[path] = caps
+	return caps, nil
+}
+
+// probeCapabilities asks the gnuplot executable at path for its version
+// and terminals.
+func probeCapabilities(path string) (*GnuplotCapabilities, error) {
+	cmd := exec.Command(path, "-e",
+		`printerr "version ", GPVAL_VERSION; printerr "patchlevel ", GPVAL_PATCHLEVEL; printerr "terminals ", GPVAL_TERMINALS`)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, &GnuplotError{Kind: ErrGnuplotNotFound, Msg: fmt.Sprintf("could not run '%s': %s", path, out), Err: err}
+	}
+	caps := &GnuplotCapabilities{Path: path}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "version":
+			caps.Version = fields[1]
+		case "patchlevel":
+			caps.Patchlevel = fields[1]
+		case "terminals":
+			caps.Terminals = fields[1:]
+		}
+	}
+	if caps.Version == "" {
+		return nil, &GnuplotError{Msg: fmt.Sprintf("could not read the version of '%s': %s", path, out)}
+	}
+	return caps, nil
+}
+
+// HasTerminal reports whether gnuplot was built with the given terminal.
+func (caps *GnuplotCapabilities) HasTerminal(name string) bool {
+	for _, terminal := range caps.Terminals {
+		if terminal == name {
+			return true
+		}
+	}
+	return false
+}
+
+// formatTerminal returns the terminal the gnuplot executable at path uses
+// to save plots in the given format.
+func formatTerminal(path, format string) (string, error) {
+	terminals, ok := gFormatTerminals[format]
+	if !ok {
+		return "", &GnuplotError{Kind: ErrUnsupportedFormat, Msg: fmt.Sprintf("invalid format '%s'", format)}
+	}
+	caps, err := capabilities(path)
+	if err != nil {
+		return "", err
+	}
+	for _, terminal := range terminals {
+		if caps.HasTerminal(terminal) {
+			return terminal, nil
+		}
+	}
+	return "", &GnuplotError{Kind: ErrUnsupportedFormat, Msg: fmt.Sprintf("format '%s' needs one of the gnuplot terminals %v, gnuplot %s patchlevel %s at '%s' has none of them",
+		format, terminals, caps.Version, caps.Patchlevel, caps.Path)}
+}
@@ -0,0 +1,22 @@
+package glot
+
+import "testing"
+
+func TestCapabilities(t *testing.T) {
+	caps, err := Capabilities()
+	if err != nil {
+		t.Skip("gnuplot is not available:", err)
+	}
+	if caps.Version == "" || len(caps.Terminals) == 0 {
+		t.Errorf("Expected a version and some terminals, got %+v", caps)
+	}
+	plot, err := NewPlot(2, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plot.Close()
+	err = plot.SetFormat("pdf")
+	if hasPdf := caps.HasTerminal("pdfcairo") || caps.HasTerminal("pdf"); hasPdf != (err == nil) {
+		t.Errorf("SetFormat(\"pdf\") returned %v with terminals %v", err, caps.Terminals)
+	}
+}
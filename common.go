@@ -1,78 +1,88 @@
 package glot
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"sort"
+	"time"
 )
 
 // SetTitle sets the title for the plot
 //
 // Usage
-//  dimensions := 3
-//  persist := false
-//  debug := false
-//  plot, _ := glot.NewPlot(dimensions, persist, debug)
-//  plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
-//  plot.SetTitle("Test Results")
+//
+//	dimensions := 3
+//	persist := false
+//	debug := false
+//	plot, _ := glot.NewPlot(dimensions, persist, debug)
+//	plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
+//	plot.SetTitle("Test Results")
 func (plot *Plot) SetTitle(title string) error {
-	return plot.Cmd(fmt.Sprintf("set title \"%s\" ", title))
+	return plot.set("title", fmt.Sprintf("set title %s ", Quote(title)))
 }
 
 // SetXLabel changes the label for the x-axis
 //
 // Usage
-//  dimensions := 3
-//  persist := false
-//  debug := false
-//  plot, _ := glot.NewPlot(dimensions, persist, debug)
-//  plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
-//  plot.SetTitle("Test Results")
-// 	plot.SetXLabel("X-Axis")
+//
+//	dimensions := 3
+//	persist := false
+//	debug := false
+//	plot, _ := glot.NewPlot(dimensions, persist, debug)
+//	plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
+//	plot.SetTitle("Test Results")
+//	plot.SetXLabel("X-Axis")
 func (plot *Plot) SetXLabel(label string) error {
-	return plot.Cmd(fmt.Sprintf("set xlabel '%s'", label))
+	return plot.set("xlabel", fmt.Sprintf("set xlabel %s", Quote(label)))
 }
 
 // SetYLabel changes the label for the y-axis
 //
 // Usage
-//  dimensions := 3
-//  persist := false
-//  debug := false
-//  plot, _ := glot.NewPlot(dimensions, persist, debug)
-//  plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
-//  plot.SetTitle("Test Results")
-// 	plot.SetYLabel("Y-Axis")
+//
+//	dimensions := 3
+//	persist := false
+//	debug := false
+//	plot, _ := glot.NewPlot(dimensions, persist, debug)
+//	plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
+//	plot.SetTitle("Test Results")
+//	plot.SetYLabel("Y-Axis")
 func (plot *Plot) SetYLabel(label string) error {
-	return plot.Cmd(fmt.Sprintf("set ylabel '%s'", label))
+	return plot.set("ylabel", fmt.Sprintf("set ylabel %s", Quote(label)))
 }
 
 // SetZLabel changes the label for the z-axis
 //
 // Usage
-//  dimensions := 3
-//  persist := false
-//  debug := false
-//  plot, _ := glot.NewPlot(dimensions, persist, debug)
-//  plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
-//  plot.SetTitle("Test Results")
-// 	plot.SetZLabel("Z-Axis")
+//
+//	dimensions := 3
+//	persist := false
+//	debug := false
+//	plot, _ := glot.NewPlot(dimensions, persist, debug)
+//	plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
+//	plot.SetTitle("Test Results")
+//	plot.SetZLabel("Z-Axis")
 func (plot *Plot) SetZLabel(label string) error {
-	return plot.Cmd(fmt.Sprintf("set zlabel '%s'", label))
+	return plot.set("zlabel", fmt.Sprintf("set zlabel %s", Quote(label)))
 }
 
 // SetLabels Functions helps to set labels for x, y, z axis  simultaneously
 //
 // Usage
-//  dimensions := 3
-//  persist := false
-//  debug := false
-//  plot, _ := glot.NewPlot(dimensions, persist, debug)
-//  plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
-//  plot.SetTitle("Test Results")
-// 	plot.SetLabels("X-axis","Y-Axis","Z-Axis")
+//
+//	dimensions := 3
+//	persist := false
+//	debug := false
+//	plot, _ := glot.NewPlot(dimensions, persist, debug)
+//	plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
+//	plot.SetTitle("Test Results")
+//	plot.SetLabels("X-axis","Y-Axis","Z-Axis")
 func (plot *Plot) SetLabels(labels ...string) error {
 	ndims := len(labels)
 	if ndims > 3 || ndims <= 0 {
-		return &gnuplotError{fmt.Sprintf("invalid number of dims '%v'", ndims)}
+		return &GnuplotError{Msg: fmt.Sprintf("invalid number of dims '%v'", ndims)}
 	}
 	var err error
 
@@ -101,60 +111,70 @@ func (plot *Plot) SetLabels(labels ...string) error {
 	return nil
 }
 
-// SetXrange changes the label for the x-axis
+// SetXrange sets the range of the x-axis to integer ends.
+// SetAxis sets ranges with float or open ends.
 //
 // Usage
-//  dimensions := 3
-//  persist := false
-//  debug := false
-//  plot, _ := glot.NewPlot(dimensions, persist, debug)
-//  plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
-//  plot.SetTitle("Test Results")
-// 	plot.SetXrange(-2,2)
+//
+//	dimensions := 3
+//	persist := false
+//	debug := false
+//	plot, _ := glot.NewPlot(dimensions, persist, debug)
+//	plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
+//	plot.SetTitle("Test Results")
+//	plot.SetXrange(-2,2)
 func (plot *Plot) SetXrange(start int, end int) error {
-	return plot.Cmd(fmt.Sprintf("set xrange [%d:%d]", start, end))
+	return plot.set("xrange", fmt.Sprintf("set xrange [%d:%d]", start, end))
 }
 
 // SetLogscale changes the label for the x-axis
 //
 // Usage
-//  dimensions := 3
-//  persist := false
-//  debug := false
-//  plot, _ := glot.NewPlot(dimensions, persist, debug)
-//  plot.SetYrange(-2, 18)
-//  plot.AddPointGroup("rates", "circle", [][]float64{{2, 4, 8, 16, 32}, {4, 7, 4, 10, 3}})
-//  plot.SetLogscale("x", 2)
+//
+//	dimensions := 3
+//	persist := false
+//	debug := false
+//	plot, _ := glot.NewPlot(dimensions, persist, debug)
+//	plot.SetYrange(-2, 18)
+//	plot.AddPointGroup("rates", "circle", [][]float64{{2, 4, 8, 16, 32}, {4, 7, 4, 10, 3}})
+//	plot.SetLogscale("x", 2)
 func (plot *Plot) SetLogscale(axis string, base int) error {
-	return plot.Cmd(fmt.Sprintf("set logscale %s %d", axis, base))
+	if err := checkAxis(axis); err != nil {
+		return err
+	}
+	return plot.set("logscale "+axis, fmt.Sprintf("set logscale %s %d", axis, base))
 }
 
-// SetYrange changes the label for the y-axis
+// SetYrange sets the range of the y-axis to integer ends.
+// SetAxis sets ranges with float or open ends.
 //
 // Usage
-//  dimensions := 3
-//  persist := false
-//  debug := false
-//  plot, _ := glot.NewPlot(dimensions, persist, debug)
-//  plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
-//  plot.SetTitle("Test Results")
-// 	plot.SetYrange(-2,2)
+//
+//	dimensions := 3
+//	persist := false
+//	debug := false
+//	plot, _ := glot.NewPlot(dimensions, persist, debug)
+//	plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
+//	plot.SetTitle("Test Results")
+//	plot.SetYrange(-2,2)
 func (plot *Plot) SetYrange(start int, end int) error {
-	return plot.Cmd(fmt.Sprintf("set yrange [%d:%d]", start, end))
+	return plot.set("yrange", fmt.Sprintf("set yrange [%d:%d]", start, end))
 }
 
-// SetZrange changes the label for the z-axis
+// SetZrange sets the range of the z-axis to integer ends.
+// SetAxis sets ranges with float or open ends.
 //
 // Usage
-//  dimensions := 3
-//  persist := false
-//  debug := false
-//  plot, _ := glot.NewPlot(dimensions, persist, debug)
-//  plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
-//  plot.SetTitle("Test Results")
-// 	plot.SetZrange(-2,2)
+//
+//	dimensions := 3
+//	persist := false
+//	debug := false
+//	plot, _ := glot.NewPlot(dimensions, persist, debug)
+//	plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
+//	plot.SetTitle("Test Results")
+//	plot.SetZrange(-2,2)
 func (plot *Plot) SetZrange(start int, end int) error {
-	return plot.Cmd(fmt.Sprintf("set zrange [%d:%d]", start, end))
+	return plot.set("zrange", fmt.Sprintf("set zrange [%d:%d]", start, end))
 }
 
 // SavePlot function is used to save the plot at this point.
@@ -162,24 +182,106 @@ func (plot *Plot) SetZrange(start int, end int) error {
 // of the same plot can be saved.
 //
 // Usage
-//  dimensions := 3
-//  persist := false
-//  debug := false
-//  plot, _ := glot.NewPlot(dimensions, persist, debug)
-//  plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
-//  plot.SetTitle("Test Results")
-// 	plot.SetZrange(-2,2)
-//  plot.SavePlot("1.jpeg")
+//
+//	dimensions := 3
+//	persist := false
+//	debug := false
+//	plot, _ := glot.NewPlot(dimensions, persist, debug)
+//	plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
+//	plot.SetTitle("Test Results")
+//	plot.SetZrange(-2,2)
+//	plot.SavePlot("1.jpeg")
 func (plot *Plot) SavePlot(filename string) (err error) {
+	return plot.SavePlotContext(context.Background(), filename)
+}
+
+// SavePlotContext is like SavePlot but kills the gnuplot process and
+// returns ctx.Err() if the plot isn't saved before ctx is done.
+func (plot *Plot) SavePlotContext(ctx context.Context, filename string) (err error) {
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
 	if plot.nplots == 0 {
-		return &gnuplotError{fmt.Sprintf("This plot has 0 curves and therefore its a redundant plot and it can't be printed.")}
+		return &GnuplotError{Kind: ErrEmptyPlot, Msg: fmt.Sprintf("This plot has 0 curves and therefore its a redundant plot and it can't be printed.")}
 	}
-	outputFormat := "set terminal " + plot.format
-	plot.CheckedCmd(outputFormat)
-	outputFileCommand := "set output" + "'" + filename + "'"
-	plot.CheckedCmd(outputFileCommand)
-	plot.CheckedCmd("replot  ")
-	return nil
+	if plot.backend == nil {
+		return &GnuplotError{Msg: "the plot is closed"}
+	}
+	return plot.render(ctx, plot.format, filename, nil)
+}
+
+// render saves the plot to filename, or writes it to w if w isn't nil.
+// A deferred plot which changed since it was last drawn is drawn once, on
+// the terminal of the format, rather than drawn on the current terminal
+// and replotted on that one.
+func (plot *Plot) render(ctx context.Context, format, filename string, w io.Writer) error {
+	renderer, ok := plot.backend.(plotRenderer)
+	cmd := ""
+	if ok && plot.stale {
+		cmd = plot.plotCommand()
+	}
+	if cmd == "" {
+		if err := plot.flush(ctx); err != nil {
+			return err
+		}
+		if w != nil {
+			return plot.backend.RenderTo(ctx, format, w)
+		}
+		return plot.backend.Render(ctx, format, filename)
+	}
+	plot.stale = false
+	start := time.Now()
+	var err error
+	if w != nil {
+		err = renderer.renderPlotTo(ctx, format, w, cmd)
+	} else {
+		err = renderer.renderPlot(ctx, format, filename, cmd)
+	}
+	plot.logCmd(ctx, cmd, nil, time.Since(start), err)
+	if err != nil {
+		plot.stale = true // Not drawn, the next save or Draw tries again.
+	}
+	return err
+}
+
+// Render writes the plot in the given format to w instead of saving it to
+// a file, which is handy to serve a plot without touching the disk.
+//
+// Usage
+//
+//	plot, _ := glot.NewPlot(1, false, false)
+//	plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
+//	plot.Render(responseWriter, "png")
+func (plot *Plot) Render(w io.Writer, format string) error {
+	return plot.RenderContext(context.Background(), w, format)
+}
+
+// RenderContext is like Render but kills the gnuplot process and returns
+// ctx.Err() if the plot isn't rendered before ctx is done.
+func (plot *Plot) RenderContext(ctx context.Context, w io.Writer, format string) error {
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	if plot.nplots == 0 {
+		return &GnuplotError{Kind: ErrEmptyPlot, Msg: fmt.Sprintf("This plot has 0 curves and therefore its a redundant plot and it can't be printed.")}
+	}
+	if plot.backend == nil {
+		return &GnuplotError{Msg: "the plot is closed"}
+	}
+	return plot.render(ctx, format, "", w)
+}
+
+// RenderBytes returns the plot rendered in the given format.
+//
+// Usage
+//
+//	plot, _ := glot.NewPlot(1, false, false)
+//	plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
+//	png, _ := plot.RenderBytes("png")
+func (plot *Plot) RenderBytes(format string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := plot.Render(&buf, format); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
 // SetFormat function is used to save the plot at this point.
@@ -187,26 +289,40 @@ func (plot *Plot) SavePlot(filename string) (err error) {
 // of the same plot can be saved.
 //
 // Usage
-//  dimensions := 3
-//  persist := false
-//  debug := false
-//  plot, _ := glot.NewPlot(dimensions, persist, debug)
-//  plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
-//  plot.SetTitle("Test Results")
-// 	plot.SetFormat("pdf")
-//  plot.SavePlot("1.pdf")
+//
+//	dimensions := 3
+//	persist := false
+//	debug := false
+//	plot, _ := glot.NewPlot(dimensions, persist, debug)
+//	plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
+//	plot.SetTitle("Test Results")
+//	plot.SetFormat("pdf")
+//	plot.SavePlot("1.pdf")
+//
 // NOTE: png is default format for saving files.
+// An error is returned if the installed gnuplot can't save plots in the
+// requested format, see Capabilities.
 func (plot *Plot) SetFormat(newformat string) error {
-	allowed := []string{
-		"png", "pdf"}
-	for _, s := range allowed {
-		if newformat == s {
-			plot.format = newformat
-			return nil
+	if _, ok := gFormatTerminals[newformat]; !ok {
+		allowed := make([]string, 0, len(gFormatTerminals))
+		for format := range gFormatTerminals {
+			allowed = append(allowed, format)
 		}
+		sort.Strings(allowed)
+		plot.mu.Lock()
+		defer plot.mu.Unlock()
+		plot.warn("format not in allowed list, keeping the current format", "format", newformat, "allowed", allowed, "current", plot.format)
+		err := &GnuplotError{Kind: ErrUnsupportedFormat, Msg: fmt.Sprintf("invalid format '%s'", newformat)}
+		return err
 	}
-	fmt.Printf("** Format '%v' not in allowed list %v\n", newformat, allowed)
-	fmt.Printf("** default to 'png'\n")
-	err := &gnuplotError{fmt.Sprintf("invalid format '%s'", newformat)}
-	return err
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	// Fail now rather than when saving if gnuplot can't make this format.
+	if checker, ok := plot.backend.(formatChecker); ok {
+		if err := checker.checkFormat(newformat); err != nil {
+			return err
+		}
+	}
+	plot.format = newformat
+	return nil
 }
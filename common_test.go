@@ -1,6 +1,9 @@
 package glot
 
-import "testing"
+import (
+	"bytes"
+	"testing"
+)
 
 func TestSetLabels(t *testing.T) {
 	dimensions := 3
@@ -23,3 +26,26 @@ func TestSetFormat(t *testing.T) {
 		t.Error("SetLabels raises error when non-supported format is passed as an argument.")
 	}
 }
+
+func TestRenderBytes(t *testing.T) {
+	plot, err := NewPlot(1, false, false)
+	if err != nil {
+		t.Skip("gnuplot is not available:", err)
+	}
+	defer plot.Close()
+	plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
+	first, err := plot.RenderBytes("png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(first, []byte("\x89PNG")) {
+		t.Errorf("Expected a png image, got %q", first)
+	}
+	second, err := plot.RenderBytes("png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Error("Expected two renders of the same plot to be equal")
+	}
+}
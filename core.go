@@ -1,15 +1,31 @@
 package glot
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"math"
 	"os"
 	"os/exec"
+	"regexp"
 	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 var gGnuplotCmd string
+var gGnuplotErr error // Why gnuplot couldn't be found, if it couldn't.
 var gGnuplotPrefix = "go-gnuplot-"
+var gSyncPrefix = "go-gnuplot-sync-"
+var gFramePrefix = "go-gnuplot-frame-"
+var gBinaryThreshold atomic.Int64 // Default number of values above which data is sent in binary.
 
 const defaultStyle = "points" // The default style for a curve
 const plotCommand = "replot"  // The default style for a curve
@@ -22,70 +38,691 @@ func min(a, b int) int {
 }
 
 // Function to intialize the package and check for GNU plot installation
-// This raises an error if GNU plot is not installed
+// If GNU plot is not installed the error is kept and reported by
+// Capabilities and NewPlot.
 func init() {
-	var err error
-
 	gnuplotExecutableName := "gnuplot"
 
 	if runtime.GOOS == "windows" {
 		gnuplotExecutableName = "gnuplot.exe"
 	}
 
-	gGnuplotCmd, err = exec.LookPath(gnuplotExecutableName)
-	if err != nil {
-		fmt.Errorf("** could not find path to 'gnuplot':\n%v\n", err)
-		fmt.Errorf("** set custom path to 'gnuplot' ")
+	gGnuplotCmd, gGnuplotErr = exec.LookPath(gnuplotExecutableName)
+	gBinaryThreshold.Store(100000)
+}
+
+// The kinds of failure reported by glot. They can be told apart with
+// errors.Is, whatever the message of the error holding them.
+var (
+	ErrGnuplotNotFound   = errors.New("gnuplot not found")
+	ErrDimensionMismatch = errors.New("dimension mismatch")
+	ErrUnknownStyle      = errors.New("unknown style")
+	ErrDuplicateGroup    = errors.New("duplicate PointGroup")
+	ErrEmptyPlot         = errors.New("empty plot")
+	ErrUnsupportedFormat = errors.New("unsupported format")
+)
+
+// GnuplotError is the error returned by glot for everything but the
+// commands gnuplot rejects, which are reported by CommandError.
+//
+// Usage
+//
+//	err := plot.AddPointGroup("Sample1", "points", [][]float64{{1, 2}})
+//	if errors.Is(err, glot.ErrDimensionMismatch) {
+//		// The data doesn't fit the plot.
+//	}
+//	var gerr *glot.GnuplotError
+//	if errors.As(err, &gerr) {
+//		fmt.Println(gerr.Msg)
+//	}
+type GnuplotError struct {
+	Kind error  // One of the Err sentinels, or nil if none applies.
+	Msg  string // What went wrong.
+	Err  error  // The underlying cause, or nil.
+}
+
+func (e *GnuplotError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Msg, e.Err)
 	}
+	return e.Msg
+}
+
+// Is reports whether target is the kind of the error.
+func (e *GnuplotError) Is(target error) bool {
+	return e.Kind != nil && e.Kind == target
 }
 
-type gnuplotError struct {
-	err string
+// Unwrap returns the underlying cause of the error.
+func (e *GnuplotError) Unwrap() error {
+	return e.Err
 }
 
-func (e *gnuplotError) Error() string {
-	return e.err
+// CommandError is returned by Cmd when gnuplot rejects a command.
+// It carries the offending command line and the message gnuplot printed
+// on its standard error.
+type CommandError struct {
+	Cmd string // The command line sent to gnuplot.
+	Msg string // The diagnostic printed by gnuplot.
+}
+
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("gnuplot: %s (command: %q)", e.Msg, e.Cmd)
 }
 
 // plotterProcess is the type for handling gnu commands.
+// It is the Backend used by the plots made by NewPlot.
 type plotterProcess struct {
-	handle *exec.Cmd
-	stdin  io.WriteCloser
+	handle   *exec.Cmd
+	ctx      context.Context // Bounds the lifetime of the gnuplot process.
+	path     string          // The gnuplot executable.
+	terminal string          // The terminal plots are saved with, if not the one matching their format.
+	stdin    io.WriteCloser
+	stderr   chan string       // Lines printed by gnuplot on its standard error.
+	stdout   *outputBuffer     // What gnuplot prints on its standard output while a plot is rendered to it.
+	nsync    int               // Number of sentinels sent so far.
+	nframe   int               // Number of plots rendered to the standard output so far.
+	once     sync.Once         // Guards the call to wait on the process.
+	waitErr  error             // Result of waiting on the process.
+	ndata    int               // Number of datablocks sent so far.
+	blocks   map[string]bool   // The datablocks currently defined in gnuplot.
+	tmpfiles map[string]string // The files holding binary data, by reference.
+	binary   int               // Number of values above which data is sent in binary.
 }
 
-// newPlotterProc function makes the plotterProcess struct
-func newPlotterProc(persist bool) (*plotterProcess, error) {
+// outputBuffer collects the standard output of the gnuplot process while
+// RenderTo waits for a plot. Everything else gnuplot prints there, like
+// plots drawn while the output is unset, is dropped.
+type outputBuffer struct {
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	capturing bool          // Whether RenderTo is waiting for a plot.
+	notify    chan struct{} // Signaled after every write.
+	done      chan struct{} // Closed once gnuplot has closed its standard output.
+}
+
+func newOutputBuffer() *outputBuffer {
+	return &outputBuffer{notify: make(chan struct{}, 1), done: make(chan struct{})}
+}
+
+func (b *outputBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	if !b.capturing {
+		b.mu.Unlock()
+		return len(p), nil
+	}
+	n, err := b.buf.Write(p)
+	b.mu.Unlock()
+	select {
+	case b.notify <- struct{}{}:
+	default:
+	}
+	return n, err
+}
+
+// capture starts or stops collecting the output, discarding everything
+// collected so far.
+func (b *outputBuffer) capture(on bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.capturing = on
+	b.buf.Reset()
+}
+
+// cut returns what was written before marker and removes it from the
+// buffer along with marker. ok is false if marker wasn't written yet.
+func (b *outputBuffer) cut(marker []byte) (data []byte, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	i := bytes.Index(b.buf.Bytes(), marker)
+	if i < 0 {
+		return nil, false
+	}
+	data = append([]byte(nil), b.buf.Next(i)...)
+	b.buf.Next(len(marker))
+	return data, true
+}
+
+// newPlotterProc function makes the plotterProcess struct.
+// The gnuplot process is killed when ctx is done.
+func newPlotterProc(ctx context.Context, cfg config) (*plotterProcess, error) {
 	procArgs := []string{}
-	if persist {
+	if cfg.persist {
 		procArgs = append(procArgs, "-persist")
 	}
-	cmd := exec.Command(gGnuplotCmd, procArgs...)
+	cmd := exec.CommandContext(ctx, cfg.path, procArgs...)
+	if len(cfg.env) > 0 {
+		cmd.Env = append(os.Environ(), cfg.env...)
+	}
+	cmd.Dir = cfg.dir
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
 		return nil, err
 	}
-	return &plotterProcess{handle: cmd, stdin: stdin}, cmd.Start()
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	proc := &plotterProcess{handle: cmd, ctx: ctx, path: cfg.path, terminal: cfg.terminal, stdin: stdin,
+		stderr: make(chan string, 64), stdout: newOutputBuffer(),
+		blocks: make(map[string]bool), tmpfiles: make(map[string]string), binary: cfg.binaryThreshold}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			proc.stderr <- scanner.Text()
+		}
+		close(proc.stderr)
+	}()
+	go func() {
+		io.Copy(proc.stdout, stdout)
+		close(proc.stdout.done)
+	}()
+	return proc, nil
+}
+
+// Exec sends a command to gnuplot followed by a sentinel and waits until
+// gnuplot echoes the sentinel back. It returns every line gnuplot printed
+// on its standard error while executing the command.
+// If ctx is done before that, the gnuplot process is killed and ctx.Err()
+// is returned.
+// A command which would make gnuplot wait for more lines, and swallow the
+// sentinel, is rejected with a *CommandError without being sent.
+func (proc *plotterProcess) Exec(ctx context.Context, cmd string) ([]string, error) {
+	if reason := incomplete(cmd); reason != "" {
+		return nil, &CommandError{Cmd: cmd, Msg: "incomplete command: " + reason}
+	}
+	if err := ctx.Err(); err != nil {
+		proc.kill()
+		return nil, err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	if ctx.Done() != nil {
+		// A write to a hung gnuplot can block, so the kill has to happen
+		// outside of this goroutine.
+		go func() {
+			select {
+			case <-ctx.Done():
+				proc.kill()
+			case <-done:
+			}
+		}()
+	}
+	proc.nsync++
+	sentinel := fmt.Sprintf("%s%d", gSyncPrefix, proc.nsync)
+	_, err := io.WriteString(proc.stdin, fmt.Sprintf("%s\nprinterr \"%s\"\n", cmd, sentinel))
+	if err != nil {
+		return nil, proc.contextErr(ctx, err)
+	}
+	var lines []string
+	for {
+		select {
+		case line, ok := <-proc.stderr:
+			if !ok {
+				if len(lines) == 0 {
+					lines = append(lines, "gnuplot process exited")
+				}
+				return nil, proc.contextErr(ctx, &CommandError{Cmd: cmd, Msg: strings.Join(lines, "\n")})
+			}
+			if line == sentinel {
+				return lines, nil
+			}
+			lines = append(lines, line)
+		case <-ctx.Done():
+			proc.kill()
+			return nil, ctx.Err()
+		case <-proc.ctx.Done():
+			proc.kill()
+			return nil, proc.ctx.Err()
+		}
+	}
+}
+
+// contextErr returns the error of whichever context caused the gnuplot
+// process to die, or err if none of them is done.
+func (proc *plotterProcess) contextErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if proc.ctx.Err() != nil {
+		return proc.ctx.Err()
+	}
+	return err
+}
+
+// kill terminates the gnuplot process and reclaims its pipes.
+func (proc *plotterProcess) kill() {
+	proc.handle.Process.Kill()
+	proc.wait()
+}
+
+// wait closes the standard input of gnuplot, which makes it exit, and
+// waits for the process to finish.
+func (proc *plotterProcess) wait() error {
+	proc.once.Do(func() {
+		proc.stdin.Close()
+		proc.waitErr = proc.handle.Wait()
+		for ref, fname := range proc.tmpfiles {
+			delete(proc.tmpfiles, ref)
+			os.Remove(fname)
+		}
+	})
+	return proc.waitErr
+}
+
+// SendData sends the columns to gnuplot as a datablock and returns the
+// name of the datablock. Columns holding more values than the binary
+// threshold are written in binary to a temporary file instead, which is
+// much faster for gnuplot to read.
+func (proc *plotterProcess) SendData(ctx context.Context, columns [][]float64) (string, error) {
+	if npoints(columns)*len(columns) > proc.binary {
+		return proc.sendBinary(columns)
+	}
+	proc.ndata++
+	name := fmt.Sprintf("$glot%d", proc.ndata)
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s << EOD\n", name)
+	writeColumns(&buf, columns)
+	buf.WriteString("EOD")
+	lines, err := proc.Exec(ctx, buf.String())
+	if err != nil {
+		return "", err
+	}
+	if err := commandError(name+" << EOD", lines); err != nil {
+		return "", err
+	}
+	proc.blocks[name] = true
+	return name, nil
+}
+
+// sendBinary writes the columns as float64 records to a temporary file
+// which is removed by ReleaseData or when gnuplot exits.
+func (proc *plotterProcess) sendBinary(columns [][]float64) (string, error) {
+	f, err := ioutil.TempFile(os.TempDir(), gGnuplotPrefix)
+	if err != nil {
+		return "", err
+	}
+	fname := f.Name()
+	w := bufio.NewWriter(f)
+	err = writeBinary(w, columns)
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(fname)
+		return "", err
+	}
+	ref := fmt.Sprintf("%s binary format=\"%s\" endian=little", Quote(fname), strings.Repeat("%float64", len(columns)))
+	if len(columns) == 1 {
+		// Like with text data, a single column is plotted against the
+		// index of the points.
+		ref += " using 0:1"
+	}
+	proc.tmpfiles[ref] = fname
+	return ref, nil
+}
+
+// ReleaseData undefines a datablock made by SendData, or removes the
+// file holding binary data.
+func (proc *plotterProcess) ReleaseData(ctx context.Context, ref string) error {
+	if fname, ok := proc.tmpfiles[ref]; ok {
+		delete(proc.tmpfiles, ref)
+		return os.Remove(fname)
+	}
+	if !proc.blocks[ref] {
+		return nil
+	}
+	delete(proc.blocks, ref)
+	cmd := "undefine " + ref
+	lines, err := proc.Exec(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	return commandError(cmd, lines)
+}
+
+// npoints returns the number of points held by the columns, which is
+// the length of the shortest column.
+func npoints(columns [][]float64) int {
+	n := 0
+	for i, column := range columns {
+		if i == 0 || len(column) < n {
+			n = len(column)
+		}
+	}
+	return n
+}
+
+// writeBinary writes the columns as little endian float64, one record
+// per point.
+func writeBinary(w io.Writer, columns [][]float64) error {
+	record := make([]byte, 8*len(columns))
+	for i, n := 0, npoints(columns); i < n; i++ {
+		for j, column := range columns {
+			binary.LittleEndian.PutUint64(record[8*j:], math.Float64bits(column[i]))
+		}
+		if _, err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeColumns writes the columns as text, one point per line.
+func writeColumns(w io.Writer, columns [][]float64) {
+	for i, n := 0, npoints(columns); i < n; i++ {
+		for j, column := range columns {
+			if j > 0 {
+				io.WriteString(w, " ")
+			}
+			fmt.Fprintf(w, "%v", column[i])
+		}
+		io.WriteString(w, "\n")
+	}
+}
+
+// Render saves the current plot to a file.
+func (proc *plotterProcess) Render(ctx context.Context, format, filename string) error {
+	return proc.renderPlot(ctx, format, filename, "replot")
+}
+
+// renderPlot saves the plot drawn by plotCmd to a file.
+func (proc *plotterProcess) renderPlot(ctx context.Context, format, filename, plotCmd string) error {
+	terminal, err := proc.formatTerminal(format)
+	if err != nil {
+		return err
+	}
+	for _, cmd := range []string{"set terminal " + terminal, "set output " + Quote(filename), plotCmd} {
+		lines, err := proc.Exec(ctx, cmd)
+		if err != nil {
+			return err
+		}
+		if err := commandError(cmd, lines); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenderTo draws the current plot on the standard output of gnuplot and
+// copies it to w. The plot is followed by a marker so that it's known
+// where it ends, and nothing printed before or after it bleeds into it.
+func (proc *plotterProcess) RenderTo(ctx context.Context, format string, w io.Writer) error {
+	return proc.renderPlotTo(ctx, format, w, "replot")
+}
+
+// renderPlotTo is like RenderTo for the plot drawn by plotCmd.
+func (proc *plotterProcess) renderPlotTo(ctx context.Context, format string, w io.Writer, plotCmd string) error {
+	terminal, err := proc.formatTerminal(format)
+	if err != nil {
+		return err
+	}
+	proc.nframe++
+	marker := fmt.Sprintf("%s%d", gFramePrefix, proc.nframe)
+	proc.stdout.capture(true)
+	defer proc.stdout.capture(false)
+	cmds := []string{
+		"set terminal push",
+		"set terminal " + terminal,
+		"set output",
+		plotCmd,
+		// Restoring the terminal also finishes the plot, which matters
+		// for formats like pdf which are only written out at the end.
+		"set terminal pop",
+		"set print \"-\"",
+		fmt.Sprintf("print \"%s\"", marker),
+		"unset print",
+	}
+	for _, cmd := range cmds {
+		lines, err := proc.Exec(ctx, cmd)
+		if err != nil {
+			return err
+		}
+		if err := commandError(cmd, lines); err != nil {
+			return err
+		}
+	}
+	for {
+		if data, ok := proc.stdout.cut([]byte(marker + "\n")); ok {
+			_, err := w.Write(data)
+			return err
+		}
+		select {
+		case <-proc.stdout.notify:
+		case <-proc.stdout.done:
+			if data, ok := proc.stdout.cut([]byte(marker + "\n")); ok {
+				_, err := w.Write(data)
+				return err
+			}
+			return &GnuplotError{Msg: "gnuplot closed its output before the end of the plot"}
+		case <-ctx.Done():
+			proc.kill()
+			return ctx.Err()
+		}
+	}
+}
+
+func (proc *plotterProcess) checkFormat(format string) error {
+	_, err := proc.formatTerminal(format)
+	return err
+}
+
+// formatTerminal returns the terminal used to save plots in the given
+// format.
+func (proc *plotterProcess) formatTerminal(format string) (string, error) {
+	if proc.terminal != "" {
+		return proc.terminal, nil
+	}
+	return formatTerminal(proc.path, format)
+}
+
+// Close waits for gnuplot to exit and kills it if ctx is done first.
+func (proc *plotterProcess) Close(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- proc.wait()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		proc.handle.Process.Kill()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// gDatablockHeader matches the first line of a datablock definition, the
+// name of the line ending it being the submatch.
+var gDatablockHeader = regexp.MustCompile(`^\s*\$\w+\s*<<\s*(\w+)\s*$`)
+
+// gInlineData matches the commands which can read inline data, and the
+// '-' data sources whose data follows the command, up to a line holding e.
+var (
+	gInlineData       = regexp.MustCompile(`^\s*(?:p|pl|plo|plot|sp|spl|splo|splot|rep|repl|replo|replot|fit|stats)\s`)
+	gInlineDataSource = regexp.MustCompile(`(?:^|[\s,])(?:'-'|"-")`)
+)
+
+// incomplete returns why gnuplot would read the lines following cmd as
+// part of it, or an empty string if cmd is complete. gnuplot goes on
+// reading after an unclosed brace block, a line ending with a backslash,
+// a datablock without its end line and inline data without its e line.
+func incomplete(cmd string) string {
+	lines := strings.Split(cmd, "\n")
+	depth := 0
+	for i := 0; i < len(lines); i++ {
+		if m := gDatablockHeader.FindStringSubmatch(lines[i]); m != nil {
+			for i++; i < len(lines) && strings.TrimSpace(lines[i]) != m[1]; i++ {
+			}
+			if i == len(lines) {
+				return fmt.Sprintf("datablock not ended by %s", m[1])
+			}
+			continue
+		}
+		if gInlineData.MatchString(lines[i]) {
+			line, _, _ := strings.Cut(lines[i], "#")
+			for n := len(gInlineDataSource.FindAllString(line, -1)); n > 0; n-- {
+				for i++; i < len(lines) && strings.TrimSpace(lines[i]) != "e"; i++ {
+				}
+				if i == len(lines) {
+					return "inline data not ended by e"
+				}
+			}
+			continue
+		}
+		depth += braceDepth(lines[i])
+	}
+	switch {
+	case depth > 0:
+		return "unclosed {"
+	case strings.HasSuffix(lines[len(lines)-1], "\\"):
+		return "trailing backslash"
+	}
+	return ""
+}
+
+// braceDepth returns the number of braces a line opens minus the number
+// it closes, leaving out strings and comments.
+func braceDepth(line string) int {
+	depth := 0
+	var quote byte // The quote of the string being read, if any.
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++ // Skips the escaped character.
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return depth
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+		}
+	}
+	return depth
+}
+
+// gDiagnostic matches the lines gnuplot prints to report an error or a
+// warning, like `         line 0: undefined variable: x` or
+// `"plot.gp" line 3: unrecognized option`. The message is the submatch.
+var gDiagnostic = regexp.MustCompile(`^\s*(?:"[^"]*"\s+)?line \d+:\s*(.*)$`)
+
+// commandError turns the errors gnuplot reported while executing a
+// command into a *CommandError. Everything else gnuplot printed, like
+// the output of print or show, and warnings are not errors.
+func commandError(cmd string, lines []string) error {
+	var msgs []string
+	for _, line := range lines {
+		m := gDiagnostic.FindStringSubmatch(line)
+		if m == nil || strings.HasPrefix(strings.ToLower(m[1]), "warning:") {
+			continue
+		}
+		msgs = append(msgs, strings.TrimSpace(line))
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return &CommandError{Cmd: cmd, Msg: strings.Join(msgs, "\n")}
 }
 
 // Cmd sends a command to the gnuplot subprocess and returns an error
 // if something bad happened in the gnuplot process.
+// Cmd waits for gnuplot to execute the command, so any error reported by
+// gnuplot is returned as a *CommandError.
+// Every call must hold a complete command: a brace block, a line continued
+// with a backslash, a datablock or the inline data of a '-' data source
+// can't be split across calls, and such a partial command is rejected with
+// a *CommandError.
 // ex:
 //
 //	fname := "foo.dat"
 //	err := p.Cmd("plot %s", fname)
 //	if err != nil {
-//	  panic(err)
+//		panic(err)
 //	}
 func (plot *Plot) Cmd(format string, a ...interface{}) error {
-	cmd := fmt.Sprintf(format, a...) + "\n"
-	n, err := io.WriteString(plot.proc.stdin, cmd)
-	if plot.debug {
-		//buf := new(bytes.Buffer)
-		//io.Copy(buf, plot.proc.handle.Stdout)
-		fmt.Printf("cmd> %v", cmd)
-		fmt.Printf("res> %v\n", n)
+	return plot.CmdContext(context.Background(), format, a...)
+}
+
+// CmdContext is like Cmd but kills the gnuplot process and returns
+// ctx.Err() if ctx is done before gnuplot has executed the command.
+func (plot *Plot) CmdContext(ctx context.Context, format string, a ...interface{}) error {
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	return plot.cmd(ctx, fmt.Sprintf(format, a...))
+}
+
+// CmdOutput is like Cmd but also returns what gnuplot printed while
+// executing the command, like the output of print, which goes to the
+// standard error of gnuplot unless "set print" says otherwise, or show.
+//
+// Usage
+//
+//	plot, _ := glot.NewPlot(2, false, false)
+//	variables, _ := plot.CmdOutput("show variables GPVAL_TERM")
+func (plot *Plot) CmdOutput(format string, a ...interface{}) (string, error) {
+	return plot.CmdOutputContext(context.Background(), format, a...)
+}
+
+// CmdOutputContext is like CmdOutput but kills the gnuplot process and
+// returns ctx.Err() if ctx is done before gnuplot has executed the command.
+func (plot *Plot) CmdOutputContext(ctx context.Context, format string, a ...interface{}) (string, error) {
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	cmd := fmt.Sprintf(format, a...)
+	lines, err := plot.exec(ctx, cmd)
+	if err != nil {
+		return "", err
 	}
-	return err
+	if err := commandError(cmd, lines); err != nil {
+		return "", err
+	}
+	var output []string
+	for _, line := range lines {
+		if !gDiagnostic.MatchString(line) {
+			output = append(output, line)
+		}
+	}
+	return strings.Join(output, "\n"), nil
+}
+
+// cmd sends an already formatted command to gnuplot.
+// The caller must hold plot.mu.
+func (plot *Plot) cmd(ctx context.Context, cmd string) error {
+	lines, err := plot.exec(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	return commandError(cmd, lines)
+}
+
+// exec sends an already formatted command to gnuplot and returns what
+// gnuplot printed on its standard error while executing it.
+// The caller must hold plot.mu.
+func (plot *Plot) exec(ctx context.Context, cmd string) ([]string, error) {
+	if plot.backend == nil {
+		return nil, &GnuplotError{Msg: "the plot is closed"}
+	}
+	start := time.Now()
+	lines, err := plot.backend.Exec(ctx, cmd)
+	plot.logCmd(ctx, cmd, lines, time.Since(start), err)
+	return lines, err
 }
 
 // CheckedCmd is a convenience wrapper around Cmd: it will error if the
@@ -101,9 +738,6 @@ func (plot *Plot) CheckedCmd(format string, a ...interface{}) {
 	}
 }
 
-// A map between os files and file names
-type tmpfilesDb map[string]*os.File
-
 // Close makes sure all resources used by the gnuplot subprocess are reclaimed.
 // This method is typically called when the Plotter instance is not needed
 // anymore. That's usually done via a defer statement:
@@ -112,16 +746,24 @@ type tmpfilesDb map[string]*os.File
 //	if err != nil { /* handle error */ }
 //	defer p.Close()
 func (plot *Plot) Close() (err error) {
-	if plot.proc != nil && plot.proc.handle != nil {
-		plot.proc.stdin.Close()
-		err = plot.proc.handle.Wait()
+	return plot.CloseContext(context.Background())
+}
+
+// CloseContext is like Close but kills the gnuplot process and returns
+// ctx.Err() if it doesn't exit before ctx is done.
+func (plot *Plot) CloseContext(ctx context.Context) (err error) {
+	plot.StopRefresh()
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	if plot.backend != nil {
+		err = plot.backend.Close(ctx)
+		plot.backend = nil
 	}
-	plot.ResetPlot()
+	plot.resetPlot()
 	return err
 }
 
 func (plot *Plot) cleanplot() (err error) {
-	plot.tmpfiles = make(tmpfilesDb)
 	plot.nplots = 0
 	return err
 }
@@ -132,11 +774,36 @@ func (plot *Plot) cleanplot() (err error) {
 //
 //	plot.ResetPlot()
 func (plot *Plot) ResetPlot() (err error) {
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	return plot.resetPlot()
+}
+
+func (plot *Plot) resetPlot() (err error) {
+	for _, pointGroup := range plot.pointGroups {
+		if rerr := plot.releaseData(pointGroup); err == nil {
+			err = rerr
+		}
+	}
 	plot.cleanplot()
-	plot.PointGroup = make(map[string]*PointGroup) // Adding a mapping between a curve name and a curve
+	plot.pointGroups = make(map[string]*PointGroup) // Adding a mapping between a curve name and a curve
+	plot.streams = make(map[string]*Stream)
+	plot.order = nil
 	return err
 }
 
+// SetBinaryThreshold sets the number of values above which the data of
+// a PointGroup is sent to gnuplot in binary rather than as text, for the
+// plots made after this call. It's only a default, the WithBinaryThreshold
+// option of New overrides it for a single plot.
+func SetBinaryThreshold(n int) {
+	gBinaryThreshold.Store(int64(n))
+}
+
+// SetCustomPathToGNUPlot sets the path of the gnuplot executable used by
+// the plots made after this call. It's only a default, the WithGnuplotPath
+// option of New overrides it for a single plot.
 func SetCustomPathToGNUPlot(path string) {
 	gGnuplotCmd = path
+	gGnuplotErr = nil
 }
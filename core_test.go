@@ -1,6 +1,13 @@
 package glot
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
 
 func TestMin(t *testing.T) {
 	var v int
@@ -9,3 +16,196 @@ func TestMin(t *testing.T) {
 		t.Error("Expected 1, got ", v)
 	}
 }
+
+func TestCmdError(t *testing.T) {
+	plot, err := NewPlot(2, false, false)
+	if err != nil {
+		t.Skip("gnuplot is not available:", err)
+	}
+	defer plot.Close()
+	err = plot.Cmd("set bogusoption")
+	cmdErr, ok := err.(*CommandError)
+	if !ok {
+		t.Fatalf("Expected a *CommandError for an invalid command, got %v", err)
+	}
+	if cmdErr.Cmd != "set bogusoption" {
+		t.Error("Expected the offending command in the error, got ", cmdErr.Cmd)
+	}
+	if err := plot.Cmd("set title 'ok'"); err != nil {
+		t.Error("Expected no error for a valid command, got ", err)
+	}
+}
+
+func TestSentinelErrors(t *testing.T) {
+	plot, _ := NewPlotWithBackend(1, NewRecorder())
+	if err := plot.SavePlot("1.png"); !errors.Is(err, ErrEmptyPlot) {
+		t.Error("Expected ErrEmptyPlot, got ", err)
+	}
+	if err := plot.AddPointGroup("Sample1", "points", [][]float64{{1}, {2}}); !errors.Is(err, ErrDimensionMismatch) {
+		t.Error("Expected ErrDimensionMismatch, got ", err)
+	}
+	plot.AddPointGroup("Sample1", "points", []float64{1, 2})
+	if err := plot.AddPointGroup("Sample1", "points", []float64{1, 2}); !errors.Is(err, ErrDuplicateGroup) {
+		t.Error("Expected ErrDuplicateGroup, got ", err)
+	}
+	if err := plot.AddPointGroup("Sample2", "wiggles", []float64{1, 2}); !errors.Is(err, ErrUnknownStyle) {
+		t.Error("Expected ErrUnknownStyle, got ", err)
+	}
+	err := plot.SetFormat("tls")
+	if !errors.Is(err, ErrUnsupportedFormat) || errors.Is(err, ErrUnknownStyle) {
+		t.Error("Expected only ErrUnsupportedFormat, got ", err)
+	}
+	var gerr *GnuplotError
+	if !errors.As(err, &gerr) || gerr.Msg != "invalid format 'tls'" {
+		t.Error("Expected a *GnuplotError with the message, got ", err)
+	}
+}
+
+func TestGnuplotNotFound(t *testing.T) {
+	defer SetCustomPathToGNUPlot(gGnuplotCmd)
+	SetCustomPathToGNUPlot("")
+	_, err := NewPlot(1, false, false)
+	if !errors.Is(err, ErrGnuplotNotFound) {
+		t.Error("Expected ErrGnuplotNotFound, got ", err)
+	}
+}
+
+func TestCmdContextCanceled(t *testing.T) {
+	plot, err := NewPlot(2, false, false)
+	if err != nil {
+		t.Skip("gnuplot is not available:", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := plot.CmdContext(ctx, "set title 'canceled'"); err != context.Canceled {
+		t.Fatal("Expected context.Canceled, got ", err)
+	}
+	if err := plot.Cmd("set title 'dead'"); err == nil {
+		t.Error("Expected an error once the gnuplot process has been killed")
+	}
+	plot.Close()
+}
+
+func TestSendDataBinary(t *testing.T) {
+	plot, err := New(WithBinaryThreshold(4))
+	if err != nil {
+		t.Skip("gnuplot is not available:", err)
+	}
+	defer plot.Close()
+	proc := plot.backend.(*plotterProcess)
+	ref, err := proc.SendData(context.Background(), [][]float64{{1, 2, 3}, {4, 1, 7}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(ref, "binary") {
+		t.Fatal("Expected the data to be sent in binary, got ", ref)
+	}
+	fname := proc.tmpfiles[ref]
+	if _, err := os.Stat(fname); err != nil {
+		t.Fatal("Expected the binary data in a file: ", err)
+	}
+	if err := proc.ReleaseData(context.Background(), ref); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(fname); !os.IsNotExist(err) {
+		t.Error("Expected the binary data file to be removed, got ", err)
+	}
+}
+
+func largeColumns(n int) [][]float64 {
+	columns := [][]float64{make([]float64, n), make([]float64, n)}
+	for i := 0; i < n; i++ {
+		columns[0][i] = float64(i) / 3
+		columns[1][i] = float64(i*i) / 7
+	}
+	return columns
+}
+
+func BenchmarkWriteColumns(b *testing.B) {
+	columns := largeColumns(1000000)
+	for i := 0; i < b.N; i++ {
+		writeColumns(ioutil.Discard, columns)
+	}
+}
+
+func BenchmarkWriteBinary(b *testing.B) {
+	columns := largeColumns(1000000)
+	for i := 0; i < b.N; i++ {
+		writeBinary(ioutil.Discard, columns)
+	}
+}
+
+func TestOutputBufferDropsUnawaitedOutput(t *testing.T) {
+	b := newOutputBuffer()
+	b.Write([]byte("plot drawn while the output is unset"))
+	b.capture(true)
+	b.Write([]byte("frame\nmarker\n"))
+	if data, ok := b.cut([]byte("marker\n")); !ok || string(data) != "frame\n" {
+		t.Errorf("Expected only the awaited frame, got %q, %v", data, ok)
+	}
+	b.capture(false)
+	b.Write([]byte("more"))
+	if b.buf.Len() != 0 {
+		t.Errorf("Expected the output to be dropped after the frame, got %q", b.buf.String())
+	}
+}
+
+func TestIncompleteCommand(t *testing.T) {
+	complete := []string{
+		"set title 'ok'",
+		"do for [i=1:3] {\n  print i\n}",
+		"if (1) { print \"{\" } # {",
+		"print '}{'",
+		"set title \\\n  'continued'",
+		"$d << EOD\n1 {\nEOD\nplot $d",
+		"plot '-' with lines, \"-\" with points\n1 2\ne\n3 4\ne",
+		"plot 'data.txt' title 'a-b' # '-'",
+	}
+	for _, cmd := range complete {
+		if reason := incomplete(cmd); reason != "" {
+			t.Errorf("Expected %q to be complete, got %q", cmd, reason)
+		}
+	}
+	partial := []string{
+		"do for [i=1:3] {",
+		"if (1) {\n  print \"}\"",
+		"set title \\",
+		"$d << EOD\n1 2",
+		"plot '-' with lines",
+		"splot \"-\"\n1 2 3",
+		"plot '-', '-'\n1 2\ne\n3 4",
+	}
+	proc := &plotterProcess{}
+	for _, cmd := range partial {
+		if _, err := proc.Exec(context.Background(), cmd); err == nil {
+			t.Errorf("Expected %q to be rejected", cmd)
+		}
+	}
+}
+
+func TestCommandError(t *testing.T) {
+	output := []string{"1", "", "	Variables beginning with GPVAL_TERM:", "	GPVAL_TERM = \"unknown\"", "         line 0: warning: Skipping data file with no valid points"}
+	if err := commandError("print 1", output); err != nil {
+		t.Errorf("Expected the output of a valid command not to be an error, got %v", err)
+	}
+	failure := []string{"set bogus", "    ^", "         line 0: unrecognized option - see 'help set'."}
+	err := commandError("set bogus", failure)
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.Msg != "line 0: unrecognized option - see 'help set'." {
+		t.Errorf("Expected the diagnostic of gnuplot, got %v", err)
+	}
+}
+
+func TestCmdOutput(t *testing.T) {
+	plot, err := NewPlot(2, false, false)
+	if err != nil {
+		t.Skip("gnuplot is not available:", err)
+	}
+	defer plot.Close()
+	if out, err := plot.CmdOutput("print %d", 41+1); err != nil || out != "42" {
+		t.Errorf("Expected the printed value, got %q, %v", out, err)
+	}
+	if err := plot.Cmd("print 1"); err != nil {
+		t.Errorf("Expected print not to fail, got %v", err)
+	}
+}
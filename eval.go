@@ -0,0 +1,77 @@
+package glot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// gEvalPrefix marks the line holding the value printed by Eval.
+var gEvalPrefix = "go-gnuplot-eval:"
+
+var variableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Eval evaluates a gnuplot expression and returns the value printed by
+// gnuplot. It can be used to read back the state of the gnuplot process,
+// for example the GPVAL_* variables which are updated after every plot.
+//
+// Usage
+//
+//	plot, _ := glot.NewPlot(1, false, false)
+//	plot.AddPointGroup("Sample1", "lines", []float64{2, 3, 4, 1})
+//	version, _ := plot.Eval("GPVAL_VERSION")
+//	terminals, _ := plot.Eval("GPVAL_TERMINALS")
+func (plot *Plot) Eval(expr string) (string, error) {
+	return plot.EvalContext(context.Background(), expr)
+}
+
+// EvalContext is like Eval but kills the gnuplot process and returns
+// ctx.Err() if ctx is done before gnuplot has printed the value.
+func (plot *Plot) EvalContext(ctx context.Context, expr string) (string, error) {
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	// printerr is used rather than print so that a "set print" issued
+	// by the user doesn't redirect the value.
+	cmd := fmt.Sprintf("printerr \"%s\", %s", gEvalPrefix, expr)
+	lines, err := plot.exec(ctx, cmd)
+	if err != nil {
+		return "", err
+	}
+	for i, line := range lines {
+		if strings.HasPrefix(line, gEvalPrefix) {
+			if err := commandError(cmd, lines[:i]); err != nil {
+				return "", err
+			}
+			value := strings.TrimPrefix(strings.TrimPrefix(line, gEvalPrefix), " ")
+			return strings.Join(append([]string{value}, lines[i+1:]...), "\n"), nil
+		}
+	}
+	if err := commandError(cmd, lines); err != nil {
+		return "", err
+	}
+	return "", &CommandError{Cmd: cmd, Msg: "no value printed"}
+}
+
+// GetVar returns the value of a numeric gnuplot variable.
+//
+// Usage
+//
+//	plot, _ := glot.NewPlot(2, false, false)
+//	plot.AddPointGroup("Sample1", "lines", [][]float64{{1, 2, 3}, {4, 1, 7}})
+//	xmin, _ := plot.GetVar("GPVAL_X_MIN")
+func (plot *Plot) GetVar(name string) (float64, error) {
+	if !variableName.MatchString(name) {
+		return 0, &GnuplotError{Msg: fmt.Sprintf("invalid variable name '%s'", name)}
+	}
+	value, err := plot.Eval(name)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, &GnuplotError{Msg: fmt.Sprintf("variable '%s' is not a number: %q", name, value)}
+	}
+	return v, nil
+}
@@ -0,0 +1,27 @@
+package glot
+
+import "testing"
+
+func TestEval(t *testing.T) {
+	plot, err := NewPlot(2, false, false)
+	if err != nil {
+		t.Skip("gnuplot is not available:", err)
+	}
+	defer plot.Close()
+	if err := plot.Cmd("glotvar = 42"); err != nil {
+		t.Fatal(err)
+	}
+	v, err := plot.GetVar("glotvar")
+	if err != nil || v != 42 {
+		t.Errorf("Expected 42, got %v (%v)", v, err)
+	}
+	if s, err := plot.Eval(`"it's a string"`); err != nil || s != "it's a string" {
+		t.Errorf("Expected the string back, got %q (%v)", s, err)
+	}
+	if _, err := plot.GetVar("glot_undefined_variable"); err == nil {
+		t.Error("Expected an error for an undefined variable")
+	}
+	if _, err := plot.GetVar("1; system('ls')"); err == nil {
+		t.Error("Expected an error for an invalid variable name")
+	}
+}
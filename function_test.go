@@ -11,7 +11,7 @@ func TestAddFunc3d(t *testing.T) {
 	plot, _ := NewPlot(dimensions, persist, debug)
 	fct := func(x, y float64) float64 { return x - y }
 	groupName := "Stright Line"
-	style := "lines"
+	style := StyleLines
 	pointsY := []float64{1, 2, 3}
 	pointsX := []float64{1, 2, 3, 4, 5}
 	err := plot.AddFunc3d(groupName, style, pointsX, pointsY, fct)
@@ -8,9 +8,12 @@
 package glot
 
 import (
+	"context"
 	"fmt"
-	"io/ioutil"
-	"os"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Plot is the basic type representing a plot.
@@ -22,58 +25,93 @@ import (
 // the time of plot construction.
 // The Pointgroups can be dynamically added and removed from a plot
 // And style changes can also be made dynamically.
+// All the methods of a Plot are safe for concurrent use by multiple
+// goroutines.
 type Plot struct {
-	proc       *plotterProcess
-	debug      bool
-	plotcmd    string
-	nplots     int                    // number of currently active plots
-	tmpfiles   tmpfilesDb             // A temporary file used for saving data
-	dimensions int                    // dimensions of the plot
-	PointGroup map[string]*PointGroup // A map between Curve name and curve type. This maps a name to a given curve in a plot. Only one curve with a given name exists in a plot.
-	format     string                 // The saving format of the plot. This could be PDF, PNG, JPEG and so on.
-	style      string                 // style of the plot
-	title      string                 // The title of the plot.
+	mu          sync.Mutex   // Guards every field below and the gnuplot process.
+	backend     Backend      // The gnuplot process, or whatever else the plot draws through.
+	id          int64        // Identifies the plot in logs.
+	logger      *slog.Logger // Where diagnostics go, if anywhere.
+	plotcmd     string
+	nplots      int                    // number of currently active plots
+	dimensions  int                    // dimensions of the plot
+	pointGroups map[string]*PointGroup // A map between Curve name and curve type. This maps a name to a given curve in a plot. Only one curve with a given name exists in a plot.
+	order       []string               // The names of the PointGroups in drawing order.
+	format      string                 // The saving format of the plot. This could be PDF, PNG, JPEG and so on.
+	streams     map[string]*Stream     // The PointGroups which are streams, by name.
+	refresher   *refresher             // Redraws the plot periodically, if StartRefresh was called.
+	timeAxis    *TimeAxis              // How the x axis of time series is labeled, nil until one is added.
+	deferred    bool                   // Whether drawing is deferred until the plot is saved or rendered.
+	stale       bool                   // Whether a deferred plot changed since it was last drawn.
+	settings    []setting              // The settings applied to the plot, replayed by WriteScript.
+	secondary   map[AxisName]bool      // The secondary axes shown in place of the mirrored ticks of their primary axis.
+	style       string                 // style of the plot
+	title       string                 // The title of the plot.
 }
 
 // NewPlot Function makes a new plot with the specified dimensions.
 //
 // Usage
-//  dimensions := 3
-//  persist := false
-//  debug := false
-//  plot, _ := glot.NewPlot(dimensions, persist, debug)
+//
+//	dimensions := 3
+//	persist := false
+//	debug := false
+//	plot, _ := glot.NewPlot(dimensions, persist, debug)
+//
 // Variable definitions
-//  dimensions  :=> refers to the dimensions of the plot.
-//  debug       :=> can be used by developers to check the actual commands sent to gnu plot.
-//  persist     :=> used to make the gnu plot window stay open.
+//
+//	dimensions  :=> refers to the dimensions of the plot.
+//	debug       :=> can be used by developers to check the actual commands sent to gnu plot.
+//	persist     :=> used to make the gnu plot window stay open.
 func NewPlot(dimensions int, persist, debug bool) (*Plot, error) {
-	p := &Plot{proc: nil, debug: debug, plotcmd: "plot",
-		nplots: 0, dimensions: dimensions, style: "points", format: "png"}
-	p.PointGroup = make(map[string]*PointGroup) // Adding a mapping between a curve name and a curve
-	p.tmpfiles = make(tmpfilesDb)
-	proc, err := newPlotterProc(persist)
+	return NewPlotContext(context.Background(), dimensions, persist, debug)
+}
+
+// NewPlotContext is like NewPlot but the gnuplot process backing the plot
+// is killed as soon as ctx is done.
+func NewPlotContext(ctx context.Context, dimensions int, persist, debug bool) (*Plot, error) {
+	return New(WithContext(ctx), WithDimensions(dimensions), WithPersist(persist), WithDebug(debug))
+}
+
+// NewPlotWithBackend makes a new plot with the specified dimensions which
+// draws through backend instead of a new gnuplot process.
+//
+// Usage
+//
+//	recorder := glot.NewRecorder()
+//	plot, _ := glot.NewPlotWithBackend(2, recorder)
+//	plot.AddPointGroup("Sample1", "points", [][]float64{{1, 2, 3}, {4, 1, 7}})
+//	recorder.Commands()
+func NewPlotWithBackend(dimensions int, backend Backend) (*Plot, error) {
+	p, err := newPlot(dimensions, false)
 	if err != nil {
 		return nil, err
 	}
+	p.backend = backend
+	return p, nil
+}
+
+// newPlot makes a plot that isn't attached to a backend yet.
+func newPlot(dimensions int, debug bool) (*Plot, error) {
 	// Only 1,2,3 Dimensional plots are supported
 	if dimensions > 3 || dimensions < 1 {
-		return nil, &gnuplotError{fmt.Sprintf("invalid number of dims '%v'", dimensions)}
+		return nil, &GnuplotError{Msg: fmt.Sprintf("invalid number of dims '%v'", dimensions)}
 	}
-	p.proc = proc
+	p := &Plot{backend: nil, id: nextPlotID(), plotcmd: "plot",
+		nplots: 0, dimensions: dimensions, style: "points", format: "png"}
+	if debug {
+		p.setLogger(debugLogger())
+	}
+	p.pointGroups = make(map[string]*PointGroup) // Adding a mapping between a curve name and a curve
+	p.streams = make(map[string]*Stream)
 	return p, nil
 }
 
 func (plot *Plot) plotX(PointGroup *PointGroup) error {
-	f, err := ioutil.TempFile(os.TempDir(), gGnuplotPrefix)
+	fname, err := plot.groupData(PointGroup, [][]float64{PointGroup.castedData.([]float64)})
 	if err != nil {
 		return err
 	}
-	fname := f.Name()
-	plot.tmpfiles[fname] = f
-	for _, d := range PointGroup.castedData.([]float64) {
-		f.WriteString(fmt.Sprintf("%v\n", d))
-	}
-	f.Close()
 	cmd := plot.plotcmd
 	if plot.nplots > 0 {
 		cmd = plotCommand
@@ -81,34 +119,16 @@ func (plot *Plot) plotX(PointGroup *PointGroup) error {
 	if PointGroup.style == "" {
 		PointGroup.style = defaultStyle
 	}
-	var line string
-	if PointGroup.name == "" {
-		line = fmt.Sprintf("%s \"%s\" with %s", cmd, fname, PointGroup.style)
-	} else {
-		line = fmt.Sprintf("%s \"%s\" title \"%s\" with %s",
-			cmd, fname, PointGroup.name, PointGroup.style)
-	}
+	line := cmd + " " + plotClause(fname, PointGroup)
 	plot.nplots++
-	return plot.Cmd(line)
+	return plot.draw(line)
 }
 
 func (plot *Plot) plotXY(PointGroup *PointGroup) error {
-	x := PointGroup.castedData.([][]float64)[0]
-	y := PointGroup.castedData.([][]float64)[1]
-	npoints := min(len(x), len(y))
-
-	f, err := ioutil.TempFile(os.TempDir(), gGnuplotPrefix)
+	fname, err := plot.groupData(PointGroup, PointGroup.castedData.([][]float64)[:2])
 	if err != nil {
 		return err
 	}
-	fname := f.Name()
-	plot.tmpfiles[fname] = f
-
-	for i := 0; i < npoints; i++ {
-		f.WriteString(fmt.Sprintf("%v %v\n", x[i], y[i]))
-	}
-
-	f.Close()
 	cmd := plot.plotcmd
 	if plot.nplots > 0 {
 		cmd = plotCommand
@@ -117,47 +137,189 @@ func (plot *Plot) plotXY(PointGroup *PointGroup) error {
 	if PointGroup.style == "" {
 		PointGroup.style = "points"
 	}
-	var line string
-	if PointGroup.name == "" {
-		line = fmt.Sprintf("%s \"%s\" with %s", cmd, fname, PointGroup.style)
-	} else {
-		line = fmt.Sprintf("%s \"%s\" title \"%s\" with %s",
-			cmd, fname, PointGroup.name, PointGroup.style)
-	}
+	line := cmd + " " + plotClause(fname, PointGroup)
 	plot.nplots++
-	return plot.Cmd(line)
+	return plot.draw(line)
 }
 
 func (plot *Plot) plotXYZ(points *PointGroup) error {
-	x := points.castedData.([][]float64)[0]
-	y := points.castedData.([][]float64)[1]
-	z := points.castedData.([][]float64)[2]
-	npoints := min(len(x), len(y))
-	npoints = min(npoints, len(z))
-	f, err := ioutil.TempFile(os.TempDir(), gGnuplotPrefix)
+	fname, err := plot.groupData(points, points.castedData.([][]float64)[:3])
 	if err != nil {
 		return err
 	}
-	fname := f.Name()
-	plot.tmpfiles[fname] = f
-
-	for i := 0; i < npoints; i++ {
-		f.WriteString(fmt.Sprintf("%v %v %v\n", x[i], y[i], z[i]))
-	}
-
-	f.Close()
 	cmd := "splot" // Force 3D plot
 	if plot.nplots > 0 {
 		cmd = plotCommand
 	}
 
-	var line string
-	if points.name == "" {
-		line = fmt.Sprintf("%s \"%s\" with %s", cmd, fname, points.style)
-	} else {
-		line = fmt.Sprintf("%s \"%s\" title \"%s\" with %s",
-			cmd, fname, points.name, points.style)
-	}
+	line := cmd + " " + plotClause(fname, points)
 	plot.nplots++
-	return plot.Cmd(line)
+	return plot.draw(line)
+}
+
+// draw sends a plot command. A deferred plot doesn't send it, flush sends
+// a single plot command drawing every PointGroup instead.
+func (plot *Plot) draw(line string) error {
+	if plot.deferred {
+		plot.stale = true
+		return nil
+	}
+	return plot.cmd(context.Background(), line)
+}
+
+// flush sends the plot command drawing every PointGroup of a deferred
+// plot if they changed since it was last sent.
+func (plot *Plot) flush(ctx context.Context) error {
+	if !plot.stale {
+		return nil
+	}
+	plot.stale = false
+	cmd := plot.plotCommand()
+	if cmd == "" {
+		return nil
+	}
+	return plot.cmd(ctx, cmd)
+}
+
+// plotCommand returns the single plot command drawing every PointGroup
+// whose data was sent, or an empty string if there is none.
+func (plot *Plot) plotCommand() string {
+	var clauses []string
+	for _, name := range plot.names() {
+		pointGroup := plot.pointGroups[name]
+		if pointGroup.ref != "" {
+			clauses = append(clauses, plotClause(pointGroup.ref, pointGroup))
+		}
+	}
+	if len(clauses) == 0 {
+		return ""
+	}
+	cmd := plot.plotcmd
+	if plot.dimensions == 3 {
+		cmd = "splot"
+	}
+	return cmd + " " + strings.Join(clauses, ", ")
+}
+
+// SetDeferred switches the plot to deferred rendering, or back to
+// immediate rendering.
+// By default every call to AddPointGroup draws the plot again. A deferred
+// plot only sends the data of its PointGroups as they are added, and draws
+// all of them with a single plot command when it's saved, rendered or
+// drawn with Draw. Settings applied in between, like ranges, are taken
+// into account by that plot command.
+//
+// Usage
+//
+//	plot, _ := glot.NewPlot(1, false, false)
+//	plot.SetDeferred(true)
+//	for i, series := range allSeries {
+//		plot.AddPointGroup(fmt.Sprint("Series ", i), "lines", series)
+//	}
+//	plot.SetXrange(0, 100)
+//	plot.SavePlot("1.png") // One plot command draws every series.
+func (plot *Plot) SetDeferred(deferred bool) error {
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	plot.deferred = deferred
+	if !deferred {
+		return plot.flush(context.Background())
+	}
+	return nil
+}
+
+// Draw draws the pending PointGroups of a deferred plot, for example to
+// update the window of a persistent plot. It does nothing for a plot
+// which isn't deferred.
+func (plot *Plot) Draw() error {
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	return plot.flush(context.Background())
+}
+
+// redraw draws every PointGroup holding some points again from scratch.
+// Only the data released since it was last sent is sent again.
+func (plot *Plot) redraw() error {
+	plot.cleanplot()
+	for _, name := range plot.names() {
+		pointGroup := plot.pointGroups[name]
+		if npoints(pointGroup.columns()) == 0 {
+			continue
+		}
+		if err := plot.plotGroup(pointGroup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// plotGroup draws a PointGroup with the function matching its dimensions.
+func (plot *Plot) plotGroup(pointGroup *PointGroup) error {
+	switch len(pointGroup.columns()) {
+	case 1:
+		return plot.plotX(pointGroup)
+	case 2:
+		return plot.plotXY(pointGroup)
+	default:
+		return plot.plotXYZ(pointGroup)
+	}
+}
+
+// plotClause returns the part of a plot command which draws a PointGroup
+// whose data is referred to by ref.
+func plotClause(ref string, pointGroup *PointGroup) string {
+	if pointGroup.times != nil {
+		// gnuplot needs to be told which column holds the times.
+		ref += " using 1:2"
+	}
+	if axes := pointGroup.options.Axes; axes != "" && axes != AxesX1Y1 {
+		ref += " axes " + string(axes)
+	}
+	options := pointGroup.options.clause()
+	switch {
+	case pointGroup.options.NoTitle:
+		return fmt.Sprintf("%s notitle with %s%s", ref, pointGroup.style, options)
+	case pointGroup.name == "":
+		return fmt.Sprintf("%s with %s%s", ref, pointGroup.style, options)
+	}
+	return fmt.Sprintf("%s title %s with %s%s", ref, Quote(pointGroup.name), pointGroup.style, options)
+}
+
+// groupData returns the reference to the data of a PointGroup, sending
+// its columns only if they were released since they were last sent, which
+// is how a change of the data is signaled.
+func (plot *Plot) groupData(pointGroup *PointGroup, columns [][]float64) (string, error) {
+	if pointGroup.ref != "" {
+		return pointGroup.ref, nil
+	}
+	return plot.sendData(pointGroup, columns)
+}
+
+// sendData sends the columns of a PointGroup to the backend, replacing
+// the data previously sent for it.
+func (plot *Plot) sendData(pointGroup *PointGroup, columns [][]float64) (string, error) {
+	if plot.backend == nil {
+		return "", &GnuplotError{Msg: "the plot is closed"}
+	}
+	if err := plot.releaseData(pointGroup); err != nil {
+		return "", err
+	}
+	start := time.Now()
+	ref, err := plot.backend.SendData(context.Background(), columns)
+	plot.logData(context.Background(), ref, columns, time.Since(start), err)
+	if err != nil {
+		return "", err
+	}
+	pointGroup.ref = ref
+	return ref, nil
+}
+
+// releaseData frees the data sent to the backend for a PointGroup.
+func (plot *Plot) releaseData(pointGroup *PointGroup) error {
+	if pointGroup.ref == "" || plot.backend == nil {
+		return nil
+	}
+	ref := pointGroup.ref
+	pointGroup.ref = ""
+	return plot.backend.ReleaseData(context.Background(), ref)
 }
@@ -1,6 +1,15 @@
 package glot
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
 
 func TestNewPlot(t *testing.T) {
 	persist := false
@@ -10,3 +19,103 @@ func TestNewPlot(t *testing.T) {
 		t.Error("Expected error when making a 0 dimensional plot.")
 	}
 }
+
+func TestNoTempFiles(t *testing.T) {
+	before, _ := filepath.Glob(filepath.Join(os.TempDir(), gGnuplotPrefix+"*"))
+	plot, err := NewPlot(1, false, false)
+	if err != nil {
+		t.Skip("gnuplot is not available:", err)
+	}
+	plot.AddPointGroup("Sample1", "points", []float64{4, 1, 7})
+	plot.AddPointGroup("Sample2", "points", []float64{2, 2, 2})
+	plot.RemovePointGroup("Sample1")
+	plot.ResetPlot()
+	plot.Close()
+	after, _ := filepath.Glob(filepath.Join(os.TempDir(), gGnuplotPrefix+"*"))
+	if len(after) != len(before) {
+		t.Errorf("Expected no temporary file to remain, found %v", after)
+	}
+}
+
+func TestDeferred(t *testing.T) {
+	recorder := NewRecorder()
+	plot, _ := NewPlotWithBackend(1, recorder)
+	plot.SetDeferred(true)
+	plot.AddPointGroup("a", "lines", []float64{1, 2})
+	plot.AddPointGroup("b", "lines", []float64{3, 4})
+	plot.AddPointGroup("c", "points", []float64{5, 6})
+	plot.SetXrange(0, 2)
+	if err := plot.SavePlot("1.png"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"set xrange [0:2]",
+		`plot $data1 title "a" with lines, $data2 title "b" with lines, $data3 title "c" with points`,
+	}
+	if got := recorder.Commands(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected commands %q, got %q", want, got)
+	}
+}
+
+// terminalRecorder is a Recorder which renders plot commands of its own,
+// like the gnuplot process does.
+type terminalRecorder struct {
+	*Recorder
+}
+
+func (r terminalRecorder) renderPlot(ctx context.Context, format, filename, plotCmd string) error {
+	for _, cmd := range []string{"set terminal " + format, "set output " + Quote(filename), plotCmd} {
+		r.Exec(ctx, cmd)
+	}
+	return nil
+}
+
+func (r terminalRecorder) renderPlotTo(ctx context.Context, format string, w io.Writer, plotCmd string) error {
+	return r.renderPlot(ctx, format, "", plotCmd)
+}
+
+func TestDeferredDrawnOnce(t *testing.T) {
+	recorder := terminalRecorder{NewRecorder()}
+	plot, _ := NewPlotWithBackend(1, recorder)
+	plot.SetDeferred(true)
+	plot.AddPointGroup("a", "lines", []float64{1, 2})
+	plot.AddPointGroup("b", "lines", []float64{3, 4})
+	if err := plot.SavePlot("1.png"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"set terminal png",
+		`set output "1.png"`,
+		`plot $data1 title "a" with lines, $data2 title "b" with lines`,
+	}
+	if got := recorder.Commands(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected commands %q, got %q", want, got)
+	}
+	recorder.Reset()
+	if _, err := plot.RenderBytes("png"); err != nil {
+		t.Fatal(err)
+	}
+	if got := recorder.Commands(); len(got) != 0 {
+		t.Errorf("Expected the unchanged plot to be replotted by the backend, got %q", got)
+	}
+}
+
+func TestClosedPlot(t *testing.T) {
+	plot, _ := NewPlotWithBackend(2, NewRecorder())
+	plot3d, _ := NewPlotWithBackend(3, NewRecorder())
+	plot.Close()
+	plot3d.Close()
+	adds := map[string]func() error{
+		"AddPointGroup": func() error { return plot.AddPointGroup("A", StylePoints, [][]float64{{1}, {2}}) },
+		"AddSeries":     func() error { return AddSeries(plot, "A", StyleLines, []int{1, 2}) },
+		"AddXY":         func() error { return AddXY(plot, "A", StyleLines, []int{1}, []int{2}) },
+		"AddXYZ":        func() error { return AddXYZ(plot3d, "A", StyleLines, []int{1}, []int{2}, []int{3}) },
+		"AddTimeSeries": func() error { return AddTimeSeries(plot, "A", StyleLines, []time.Time{time.Now()}, []int{1}) },
+	}
+	for name, add := range adds {
+		var gerr *GnuplotError
+		if err := add(); !errors.As(err, &gerr) || gerr.Msg != "the plot is closed" {
+			t.Errorf("%s: expected the plot to be closed, got %v", name, err)
+		}
+	}
+}
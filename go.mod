@@ -0,0 +1,3 @@
+module github.com/Arafatk/glot
+
+go 1.21
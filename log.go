@@ -0,0 +1,89 @@
+package glot
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+var gPlotID int64 // Number of plots made so far, which identifies them in logs.
+
+// SetLogger sends the diagnostics of the plot to logger: every command
+// sent to gnuplot at debug level, and the warnings of methods like
+// AddPointGroup at warning level. A nil logger turns diagnostics off.
+// glot never writes diagnostics to the standard output by itself.
+//
+// Usage
+//
+//	plot, _ := glot.NewPlot(2, false, false)
+//	plot.SetLogger(slog.New(slog.NewJSONHandler(os.Stderr, nil)))
+func (plot *Plot) SetLogger(logger *slog.Logger) {
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	plot.setLogger(logger)
+}
+
+// setLogger sets the logger of the plot, tagging everything it logs with
+// the identity of the plot.
+func (plot *Plot) setLogger(logger *slog.Logger) {
+	if logger != nil {
+		logger = logger.With("plot", plot.id)
+	}
+	plot.logger = logger
+}
+
+// debugLogger is the logger of the plots made in debug mode without a
+// logger of their own.
+func debugLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+// nextPlotID returns the identity of a new plot.
+func nextPlotID() int64 {
+	return atomic.AddInt64(&gPlotID, 1)
+}
+
+// logCmd logs a command sent to the backend.
+func (plot *Plot) logCmd(ctx context.Context, cmd string, output []string, elapsed time.Duration, err error) {
+	if plot.logger == nil {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("cmd", cmd),
+		slog.Int("bytes", len(cmd)),
+		slog.Duration("duration", elapsed),
+	}
+	if len(output) > 0 {
+		attrs = append(attrs, slog.Any("output", output))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	plot.logger.LogAttrs(ctx, slog.LevelDebug, "gnuplot command", attrs...)
+}
+
+// logData logs data sent to the backend.
+func (plot *Plot) logData(ctx context.Context, ref string, columns [][]float64, elapsed time.Duration, err error) {
+	if plot.logger == nil {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("ref", ref),
+		slog.Int("points", npoints(columns)),
+		slog.Int("values", len(columns)*npoints(columns)),
+		slog.Duration("duration", elapsed),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	plot.logger.LogAttrs(ctx, slog.LevelDebug, "gnuplot data", attrs...)
+}
+
+// warn logs something the caller probably didn't mean.
+func (plot *Plot) warn(msg string, args ...interface{}) {
+	if plot.logger != nil {
+		plot.logger.Warn(msg, args...)
+	}
+}
@@ -0,0 +1,48 @@
+package glot
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestSetLogger(t *testing.T) {
+	var buf bytes.Buffer
+	plot, _ := NewPlotWithBackend(1, NewRecorder())
+	plot.SetLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	plot.AddPointGroup("Sample1", "wiggles", []float64{1, 2})
+	plot.SetTitle("Test")
+	var records []map[string]interface{}
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var record map[string]interface{}
+		if err := json.Unmarshal(line, &record); err != nil {
+			t.Fatal(err)
+		}
+		if record["plot"] != float64(plot.id) {
+			t.Errorf("Expected the plot identity in %v", record)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 4 {
+		t.Fatalf("Expected data, a command, a warning and a command to be logged, got %v", records)
+	}
+	if records[0]["msg"] != "gnuplot data" || records[0]["points"] != float64(2) {
+		t.Errorf("Expected the data to be logged, got %v", records[0])
+	}
+	if records[1]["msg"] != "gnuplot command" || records[1]["cmd"] != `plot $data1 title "Sample1" with points` {
+		t.Errorf("Expected the plot command to be logged, got %v", records[1])
+	}
+	if records[2]["level"] != "WARN" || records[2]["style"] != "wiggles" {
+		t.Errorf("Expected a warning about the style, got %v", records[2])
+	}
+	cmd := records[3]["cmd"].(string)
+	if records[3]["bytes"] != float64(len(cmd)) || records[3]["duration"] == nil {
+		t.Errorf("Expected the size and duration of the command, got %v", records[3])
+	}
+	plot.SetLogger(nil)
+	plot.SetTitle("Quiet")
+	if n := bytes.Count(buf.Bytes(), []byte("\n")); n != 4 {
+		t.Errorf("Expected nothing to be logged without a logger, got %d records", n)
+	}
+}
@@ -0,0 +1,131 @@
+package glot
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Number is any numeric type glot can plot, defined types included.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// AddSeries adds a PointGroup drawing the values of y against their index
+// to a plot.
+//
+// Usage
+//
+//	type Celsius float64
+//	plot, _ := glot.NewPlot(1, false, false)
+//	glot.AddSeries(plot, "Temperature", glot.StyleLines, []Celsius{21.5, 22, 23.1})
+//	glot.AddSeries(plot, "Requests", glot.StyleSteps, []uint64{120, 98, 143})
+func AddSeries[T Number](plot *Plot, name string, style Style, y []T, opts ...SeriesOptions) error {
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	return plot.addPointGroup(name, style, y, [][]float64{toFloat64(y)}, false, opts)
+}
+
+// AddXY adds a PointGroup drawing the points (x[i], y[i]) to a 2-d plot.
+//
+// Usage
+//
+//	plot, _ := glot.NewPlot(2, false, false)
+//	glot.AddXY(plot, "Latency", glot.StyleLinesPoints, []uint32{1, 2, 3}, []float32{0.5, 0.7, 0.6})
+func AddXY[X, Y Number](plot *Plot, name string, style Style, x []X, y []Y, opts ...SeriesOptions) error {
+	if len(x) != len(y) {
+		return &GnuplotError{Kind: ErrDimensionMismatch, Msg: fmt.Sprintf("x has %d values but y has %d", len(x), len(y))}
+	}
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	columns := [][]float64{toFloat64(x), toFloat64(y)}
+	return plot.addPointGroup(name, style, columns, columns, true, opts)
+}
+
+// AddXYZ adds a PointGroup drawing the points (x[i], y[i], z[i]) to a 3-d
+// plot.
+//
+// Usage
+//
+//	plot, _ := glot.NewPlot(3, false, false)
+//	glot.AddXYZ(plot, "Path", glot.StyleLines, []int{0, 1, 2}, []int{0, 1, 4}, []float64{0, 0.5, 0.25})
+func AddXYZ[X, Y, Z Number](plot *Plot, name string, style Style, x []X, y []Y, z []Z, opts ...SeriesOptions) error {
+	if len(x) != len(y) || len(x) != len(z) {
+		return &GnuplotError{Kind: ErrDimensionMismatch, Msg: fmt.Sprintf("x, y and z have %d, %d and %d values", len(x), len(y), len(z))}
+	}
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	columns := [][]float64{toFloat64(x), toFloat64(y), toFloat64(z)}
+	return plot.addPointGroup(name, style, columns, columns, true, opts)
+}
+
+// toFloat64 converts numbers to float64. A []float64 is returned as it is.
+func toFloat64[T Number](values []T) []float64 {
+	if f, ok := any(values).([]float64); ok {
+		return f
+	}
+	converted := make([]float64, len(values))
+	for i, v := range values {
+		converted[i] = float64(v)
+	}
+	return converted
+}
+
+// dataColumns converts the data given to AddPointGroup, a slice of
+// numbers or a slice of slices of numbers of any numeric type, to columns
+// of float64, and tells whether it was a slice of slices.
+func dataColumns(data interface{}) (columns [][]float64, nested bool, err error) {
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice {
+		return nil, false, invalidData(v)
+	}
+	if column, ok := reflectColumn(v); ok {
+		return [][]float64{column}, false, nil
+	}
+	if v.Type().Elem().Kind() != reflect.Slice {
+		return nil, false, invalidData(v)
+	}
+	columns = make([][]float64, v.Len())
+	for i := range columns {
+		column, ok := reflectColumn(v.Index(i))
+		if !ok {
+			return nil, false, invalidData(v)
+		}
+		columns[i] = column
+	}
+	return columns, true, nil
+}
+
+// reflectColumn converts a slice of numbers to float64. A []float64 is
+// returned as it is.
+func reflectColumn(v reflect.Value) ([]float64, bool) {
+	if v.Type() == reflect.TypeOf([]float64(nil)) {
+		return v.Interface().([]float64), true
+	}
+	column := make([]float64, v.Len())
+	switch v.Type().Elem().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		for i := range column {
+			column[i] = float64(v.Index(i).Int())
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		for i := range column {
+			column[i] = float64(v.Index(i).Uint())
+		}
+	case reflect.Float32, reflect.Float64:
+		for i := range column {
+			column[i] = v.Index(i).Float()
+		}
+	default:
+		return nil, false
+	}
+	return column, true
+}
+
+func invalidData(v reflect.Value) error {
+	if !v.IsValid() {
+		return &GnuplotError{Kind: ErrDimensionMismatch, Msg: "invalid data, expected a slice of numbers or a slice of slices of numbers"}
+	}
+	return &GnuplotError{Kind: ErrDimensionMismatch, Msg: fmt.Sprintf("invalid data of type %v, expected a slice of numbers or a slice of slices of numbers", v.Type())}
+}
@@ -0,0 +1,94 @@
+package glot
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type celsius float64
+
+type count uint16
+
+func lastData(recorder *Recorder) [][]float64 {
+	records := recorder.Records()
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].Kind == RecordData {
+			return records[i].Data
+		}
+	}
+	return nil
+}
+
+func TestAddSeries(t *testing.T) {
+	recorder := NewRecorder()
+	plot, _ := NewPlotWithBackend(1, recorder)
+	if err := AddSeries(plot, "Temperature", StyleLines, []celsius{21.5, 22}); err != nil {
+		t.Fatal(err)
+	}
+	if data := lastData(recorder); !reflect.DeepEqual(data, [][]float64{{21.5, 22}}) {
+		t.Errorf("Expected the temperatures, got %v", data)
+	}
+	if err := AddSeries(plot, "Requests", StyleSteps, []uint64{120, 98}); err != nil {
+		t.Fatal(err)
+	}
+	if data := lastData(recorder); !reflect.DeepEqual(data, [][]float64{{120, 98}}) {
+		t.Errorf("Expected the requests, got %v", data)
+	}
+}
+
+func TestAddXYAndXYZ(t *testing.T) {
+	recorder := NewRecorder()
+	plot, _ := NewPlotWithBackend(2, recorder)
+	if err := AddXY(plot, "Latency", StyleLines, []uint32{1, 2}, []float32{0.5, 0.25}); err != nil {
+		t.Fatal(err)
+	}
+	if data := lastData(recorder); !reflect.DeepEqual(data, [][]float64{{1, 2}, {0.5, 0.25}}) {
+		t.Errorf("Expected the points, got %v", data)
+	}
+	if err := AddXY(plot, "Short", StyleLines, []int{1, 2}, []int{1}); !errors.Is(err, ErrDimensionMismatch) {
+		t.Error("Expected ErrDimensionMismatch for slices of different lengths, got ", err)
+	}
+	if err := AddXYZ(plot, "Path", StyleLines, []int{1}, []int{1}, []int{1}); !errors.Is(err, ErrDimensionMismatch) {
+		t.Error("Expected ErrDimensionMismatch for 3-d points on a 2-d plot, got ", err)
+	}
+	plot3d, _ := NewPlotWithBackend(3, recorder)
+	if err := AddXYZ(plot3d, "Path", StyleLines, []int8{0, 1}, []count{2, 3}, []float64{0.5, 1}); err != nil {
+		t.Fatal(err)
+	}
+	if data := lastData(recorder); !reflect.DeepEqual(data, [][]float64{{0, 1}, {2, 3}, {0.5, 1}}) {
+		t.Errorf("Expected the points, got %v", data)
+	}
+}
+
+func TestAddPointGroupTypes(t *testing.T) {
+	tests := []struct {
+		dimensions int
+		data       interface{}
+		want       [][]float64
+	}{
+		{1, []uint8{1, 2}, [][]float64{{1, 2}}},
+		{1, []celsius{-1.5}, [][]float64{{-1.5}}},
+		{1, [][]int{{4, 5}}, [][]float64{{4, 5}}},
+		{2, [][]count{{1, 2}, {3, 4}}, [][]float64{{1, 2}, {3, 4}}},
+		{3, [][]int{{1, 2}, {3, 4}, {5, 6}}, [][]float64{{1, 2}, {3, 4}, {5, 6}}},
+		{3, [][]int16{{1}, {2}, {3}}, [][]float64{{1}, {2}, {3}}},
+	}
+	for _, test := range tests {
+		recorder := NewRecorder()
+		plot, _ := NewPlotWithBackend(test.dimensions, recorder)
+		if err := plot.AddPointGroup("Sample1", StylePoints, test.data); err != nil {
+			t.Errorf("%T: %v", test.data, err)
+			continue
+		}
+		if data := lastData(recorder); !reflect.DeepEqual(data, test.want) {
+			t.Errorf("%T: expected %v, got %v", test.data, test.want, data)
+		}
+	}
+	plot, _ := NewPlotWithBackend(2, NewRecorder())
+	for _, data := range []interface{}{nil, "12", []string{"1"}, [][]int{{1}}} {
+		if err := plot.AddPointGroup("Sample1", StylePoints, data); !errors.Is(err, ErrDimensionMismatch) {
+			t.Errorf("Expected ErrDimensionMismatch for %#v, got %v", data, err)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package glot
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Option configures a plot made by New.
+type Option func(*config)
+
+// config holds everything New needs to make a plot.
+type config struct {
+	ctx        context.Context
+	dimensions int
+	persist    bool
+	debug      bool
+	path       string       // The gnuplot executable.
+	env        []string     // Variables added to the environment of gnuplot.
+	dir        string       // The working directory of gnuplot.
+	logger     *slog.Logger // Where diagnostics go, if anywhere.
+	terminal   string       // The terminal plots are saved with.
+	session    string       // Where the session of the plot is recorded, if anywhere.
+
+	binaryThreshold int // Number of values above which data is sent in binary.
+}
+
+// defaultConfig returns the configuration of a plot made by New without
+// any option.
+func defaultConfig() config {
+	return config{ctx: context.Background(), dimensions: 2, path: gGnuplotCmd,
+		binaryThreshold: int(gBinaryThreshold.Load())}
+}
+
+// New makes a new plot backed by its own gnuplot process, configured by
+// opts. Without options the plot is a 2-d plot drawn by the gnuplot found
+// when the package was loaded or set with SetCustomPathToGNUPlot.
+//
+// Usage
+//
+//	plot, _ := glot.New(
+//		glot.WithDimensions(3),
+//		glot.WithGnuplotPath("/opt/gnuplot/bin/gnuplot"),
+//		glot.WithWorkDir("plots"),
+//		glot.WithTerminal("pngcairo size 1024,768"),
+//	)
+//	plot.AddPointGroup("Sample1", "points", [][]float64{{1, 2}, {3, 4}, {5, 6}})
+//	plot.SavePlot("1.png") // Saved as plots/1.png.
+func New(opts ...Option) (*Plot, error) {
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	p, err := newPlot(cfg.dimensions, cfg.debug)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := capabilities(cfg.path); err != nil {
+		return nil, err
+	}
+	proc, err := newPlotterProc(cfg.ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	p.backend = proc
+	if cfg.logger != nil {
+		p.setLogger(cfg.logger)
+	}
+	if cfg.session != "" {
+		if err := p.recordSession(cfg.session); err != nil {
+			proc.Close(context.Background())
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// WithContext kills the gnuplot process backing the plot as soon as ctx
+// is done.
+func WithContext(ctx context.Context) Option {
+	return func(cfg *config) {
+		cfg.ctx = ctx
+	}
+}
+
+// WithDimensions sets the dimensions of the plot, 2 by default.
+func WithDimensions(dimensions int) Option {
+	return func(cfg *config) {
+		cfg.dimensions = dimensions
+	}
+}
+
+// WithPersist makes the gnuplot window stay open after the plot is closed.
+func WithPersist(persist bool) Option {
+	return func(cfg *config) {
+		cfg.persist = persist
+	}
+}
+
+// WithDebug logs every command sent to gnuplot and what gnuplot answered
+// on the standard error, unless WithLogger gives a logger to use instead.
+func WithDebug(debug bool) Option {
+	return func(cfg *config) {
+		cfg.debug = debug
+	}
+}
+
+// WithGnuplotPath draws the plot with the gnuplot executable at path
+// instead of the default one.
+func WithGnuplotPath(path string) Option {
+	return func(cfg *config) {
+		cfg.path = path
+	}
+}
+
+// WithEnv adds variables, in the "key=value" form, to the environment
+// gnuplot runs in.
+func WithEnv(env ...string) Option {
+	return func(cfg *config) {
+		cfg.env = append(cfg.env, env...)
+	}
+}
+
+// WithWorkDir runs gnuplot in dir, which relative file names given to
+// SavePlot are then relative to.
+func WithWorkDir(dir string) Option {
+	return func(cfg *config) {
+		cfg.dir = dir
+	}
+}
+
+// WithLogger sends the diagnostics of the plot to logger, see
+// Plot.SetLogger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(cfg *config) {
+		cfg.logger = logger
+	}
+}
+
+// WithTerminal saves and renders the plot with the given gnuplot terminal,
+// options included, rather than with the terminal matching the format.
+func WithTerminal(terminal string) Option {
+	return func(cfg *config) {
+		cfg.terminal = terminal
+	}
+}
+
+// WithSession records the session of the plot to the file at path, see
+// Plot.RecordSession.
+func WithSession(path string) Option {
+	return func(cfg *config) {
+		cfg.session = path
+	}
+}
+
+// WithBinaryThreshold sends the data of a PointGroup holding more than n
+// values to gnuplot in binary rather than as text, see SetBinaryThreshold.
+func WithBinaryThreshold(n int) Option {
+	return func(cfg *config) {
+		cfg.binaryThreshold = n
+	}
+}
@@ -0,0 +1,31 @@
+package glot
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewOptions(t *testing.T) {
+	if _, err := New(WithDimensions(4)); err == nil {
+		t.Error("Expected an error for a 4-d plot")
+	}
+	if _, err := New(WithGnuplotPath(filepath.Join(t.TempDir(), "gnuplot"))); !errors.Is(err, ErrGnuplotNotFound) {
+		t.Error("Expected ErrGnuplotNotFound for a missing gnuplot, got ", err)
+	}
+	dir := t.TempDir()
+	plot, err := New(WithDimensions(1), WithWorkDir(dir), WithEnv("GNUPLOT_LIB="+dir))
+	if err != nil {
+		t.Skip("gnuplot is not available:", err)
+	}
+	defer plot.Close()
+	plot.AddPointGroup("Sample1", "lines", []float64{1, 2, 3})
+	if err := plot.SavePlot("1.png"); err != nil {
+		t.Fatal(err)
+	}
+	plot.Close()
+	if _, err := os.Stat(filepath.Join(dir, "1.png")); err != nil {
+		t.Error("Expected the plot to be saved in the working directory: ", err)
+	}
+}
@@ -1,271 +1,218 @@
 package glot
 
 import (
+	"context"
 	"fmt"
+	"time"
 )
 
 // A PointGroup refers to a set of points that need to plotted.
 // It could either be a set of points or a function of co-ordinates.
 // For Example z = Function(x,y)(3 Dimensional) or  y = Function(x) (2-Dimensional)
 type PointGroup struct {
-	name       string      // Name of the curve
-	dimensions int         // dimensions of the curve
-	style      string      // current plotting style
-	data       interface{} // Data inside the curve in any integer/float format
-	castedData interface{} // The data inside the curve typecasted to float64
-	set        bool        //
+	name       string        // Name of the curve
+	dimensions int           // dimensions of the curve
+	style      Style         // current plotting style
+	options    SeriesOptions // How the points are drawn on top of the style.
+	data       interface{}   // Data inside the curve in any integer/float format
+	castedData interface{}   // The data inside the curve typecasted to float64
+	times      []time.Time   // The x coordinates of a time series, nil for other PointGroups.
+	ref        string        // How plot commands refer to the data sent to the backend.
+	set        bool          //
 }
 
-// AddPointGroup function adds a group of points to a plot.
-//
-// Usage
-//  dimensions := 2
-//  persist := false
-//  debug := false
-//  plot, _ := glot.NewPlot(dimensions, persist, debug)
-//  plot.AddPointGroup("Sample1", "points", []int32{51, 8, 4, 11})
-//  plot.AddPointGroup("Sample2", "points", []int32{1, 2, 4, 11})
-//  plot.SavePlot("1.png")
-func (plot *Plot) AddPointGroup(name string, style string, data interface{}) (err error) {
-	_, exists := plot.PointGroup[name]
-	if exists {
-		return &gnuplotError{fmt.Sprintf("A PointGroup with the name %s  already exists, please use another name of the curve or remove this curve before using another one with the same name.", name)}
-	}
+// Name returns the name of the PointGroup.
+func (pointGroup *PointGroup) Name() string {
+	return pointGroup.name
+}
 
-	curve := &PointGroup{name: name, dimensions: plot.dimensions, data: data, set: true}
-	allowed := []string{
-		"lines", "points", "linepoints",
-		"impulses", "dots", "bar",
-		"steps", "fill solid", "histogram", "circle",
-		"errorbars", "boxerrorbars",
-		"boxes", "lp"}
-	curve.style = defaultStyle
-	discovered := 0
-	for _, s := range allowed {
-		if s == style {
-			curve.style = style
-			err = nil
-			discovered = 1
-		}
-	}
-	switch data.(type) {
-	case [][]float64:
-		if plot.dimensions != len(data.([][]float64)) {
-			return &gnuplotError{fmt.Sprintf("The dimensions of this PointGroup are not compatible with the dimensions of the plot.\nIf you want to make a 2-d curve you must specify a 2-d plot.")}
-		}
-		curve.castedData = data.([][]float64)
-		if plot.dimensions == 2 {
-			plot.plotXY(curve)
-		} else {
-			plot.plotXYZ(curve)
-		}
-		plot.PointGroup[name] = curve
+// Style returns the plotting style of the PointGroup.
+func (pointGroup *PointGroup) Style() Style {
+	return pointGroup.style
+}
 
-	case [][]float32:
-		if plot.dimensions != len(data.([][]float32)) {
-			return &gnuplotError{fmt.Sprintf("The dimensions of this PointGroup are not compatible with the dimensions of the plot.\nIf you want to make a 2-d curve you must specify a 2-d plot.")}
-		}
-		originalSlice := data.([][]float32)
-		typeCasteSlice := make([][]float64, len(originalSlice))
-		for i := 0; i < len(originalSlice); i++ {
-			typeCasteSlice[i] = make([]float64, len(originalSlice[i]))
-			for j := 0; j < len(originalSlice[i]); j++ {
-				typeCasteSlice[i][j] = float64(originalSlice[i][j])
-			}
-		}
-		curve.castedData = typeCasteSlice
-		if plot.dimensions == 2 {
-			plot.plotXY(curve)
-		} else {
-			plot.plotXYZ(curve)
-		}
-		plot.PointGroup[name] = curve
+// Options returns the options the PointGroup is drawn with.
+func (pointGroup *PointGroup) Options() SeriesOptions {
+	return pointGroup.options
+}
 
-	case [][]int:
-		if plot.dimensions != len(data.([][]int)) {
-			return &gnuplotError{fmt.Sprintf("The dimensions of this PointGroup are not compatible with the dimensions of the plot.\nIf you want to make a 2-d curve you must specify a 2-d plot.")}
-		}
-		originalSlice := data.([][]int)
-		if len(originalSlice) != 2 {
-			return &gnuplotError{fmt.Sprintf("this is not a 2d matrix")}
-		}
-		typeCasteSlice := make([][]float64, len(originalSlice))
-		for i := 0; i < len(originalSlice); i++ {
-			typeCasteSlice[i] = make([]float64, len(originalSlice[i]))
-			for j := 0; j < len(originalSlice[i]); j++ {
-				typeCasteSlice[i][j] = float64(originalSlice[i][j])
-			}
-		}
-		curve.castedData = typeCasteSlice
-		if plot.dimensions == 2 {
-			plot.plotXY(curve)
-		} else {
-			plot.plotXYZ(curve)
-		}
-		plot.PointGroup[name] = curve
+// Dimensions returns the dimensions of the PointGroup.
+func (pointGroup *PointGroup) Dimensions() int {
+	return pointGroup.dimensions
+}
 
-	case [][]int8:
-		if plot.dimensions != len(data.([][]int8)) {
-			return &gnuplotError{fmt.Sprintf("The dimensions of this PointGroup are not compatible with the dimensions of the plot.\nIf you want to make a 2-d curve you must specify a 2-d plot.")}
-		}
-		originalSlice := data.([][]int8)
-		if len(originalSlice) != 2 {
-			return &gnuplotError{fmt.Sprintf("this is not a 2d matrix")}
-		}
-		typeCasteSlice := make([][]float64, len(originalSlice))
-		for i := 0; i < len(originalSlice); i++ {
-			typeCasteSlice[i] = make([]float64, len(originalSlice[i]))
-			for j := 0; j < len(originalSlice[i]); j++ {
-				typeCasteSlice[i][j] = float64(originalSlice[i][j])
-			}
-		}
-		curve.castedData = typeCasteSlice
+// columns returns the data of the PointGroup as one slice per coordinate.
+func (pointGroup *PointGroup) columns() [][]float64 {
+	switch data := pointGroup.castedData.(type) {
+	case []float64:
+		return [][]float64{data}
+	case [][]float64:
+		return data
+	}
+	return nil
+}
 
-		if plot.dimensions == 2 {
-			plot.plotXY(curve)
-		} else {
-			plot.plotXYZ(curve)
-		}
-		plot.PointGroup[name] = curve
+// PointGroup returns a copy of the PointGroup with the given name, and
+// whether such a PointGroup exists in the plot.
+func (plot *Plot) PointGroup(name string) (*PointGroup, bool) {
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	pointGroup, exists := plot.pointGroups[name]
+	if !exists {
+		return nil, false
+	}
+	copied := *pointGroup
+	return &copied, true
+}
 
-	case [][]int16:
-		if plot.dimensions != len(data.([][]int16)) {
-			return &gnuplotError{fmt.Sprintf("The dimensions of this PointGroup are not compatible with the dimensions of the plot.\nIf you want to make a 2-d curve you must specify a 2-d plot.")}
-		}
-		originalSlice := data.([][]int16)
-		if len(originalSlice) != 2 {
-			return &gnuplotError{fmt.Sprintf("this is not a 2d matrix")}
-		}
-		typeCasteSlice := make([][]float64, len(originalSlice))
-		for i := 0; i < len(originalSlice); i++ {
-			typeCasteSlice[i] = make([]float64, len(originalSlice[i]))
-			for j := 0; j < len(originalSlice[i]); j++ {
-				typeCasteSlice[i][j] = float64(originalSlice[i][j])
-			}
-		}
-		curve.castedData = typeCasteSlice
+// PointGroupNames returns the names of all the PointGroups in the plot in
+// drawing order, the last one being drawn on top.
+func (plot *Plot) PointGroupNames() []string {
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	return plot.names()
+}
 
-		if plot.dimensions == 2 {
-			plot.plotXY(curve)
-		} else {
-			plot.plotXYZ(curve)
-		}
-		plot.PointGroup[name] = curve
+// names returns the names of the PointGroups in drawing order.
+func (plot *Plot) names() []string {
+	return append([]string(nil), plot.order...)
+}
 
-	case [][]int32:
-		if plot.dimensions != len(data.([][]int32)) {
-			return &gnuplotError{fmt.Sprintf("The dimensions of this PointGroup are not compatible with the dimensions of the plot.\nIf you want to make a 2-d curve you must specify a 2-d plot.")}
-		}
-		originalSlice := data.([][]int32)
-		if len(originalSlice) != 2 {
-			return &gnuplotError{fmt.Sprintf("this is not a 2d matrix")}
-		}
-		typeCasteSlice := make([][]float64, len(originalSlice))
-		for i := 0; i < len(originalSlice); i++ {
-			typeCasteSlice[i] = make([]float64, len(originalSlice[i]))
-			for j := 0; j < len(originalSlice[i]); j++ {
-				typeCasteSlice[i][j] = float64(originalSlice[i][j])
-			}
+// index returns the position of a PointGroup in the drawing order, or -1.
+func (plot *Plot) index(name string) int {
+	for i, n := range plot.order {
+		if n == name {
+			return i
 		}
-		curve.castedData = typeCasteSlice
+	}
+	return -1
+}
 
-		if plot.dimensions == 2 {
-			plot.plotXY(curve)
-		} else {
-			plot.plotXYZ(curve)
-		}
-		plot.PointGroup[name] = curve
+// MovePointGroup moves a PointGroup to the given position in the drawing
+// order and draws the plot again. PointGroups are drawn from position 0
+// on, so the PointGroup at the last position is drawn on top of the others
+// and comes last in the legend.
+//
+// Usage
+//
+//	plot, _ := glot.NewPlot(1, false, false)
+//	plot.AddPointGroup("Sample1", "points", []int32{51, 8, 4, 11})
+//	plot.AddPointGroup("Sample2", "points", []int32{1, 2, 4, 11})
+//	plot.MovePointGroup("Sample2", 0) // Sample1 is now drawn on top.
+func (plot *Plot) MovePointGroup(name string, index int) error {
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	return plot.movePointGroup(name, index)
+}
 
-	case [][]int64:
-		if plot.dimensions != len(data.([][]int64)) {
-			return &gnuplotError{fmt.Sprintf("The dimensions of this PointGroup are not compatible with the dimensions of the plot.\nIf you want to make a 2-d curve you must specify a 2-d plot.")}
-		}
-		originalSlice := data.([][]int64)
-		if len(originalSlice) != 2 {
-			return &gnuplotError{fmt.Sprintf("this is not a 2d matrix")}
-		}
-		typeCasteSlice := make([][]float64, len(originalSlice))
-		for i := 0; i < len(originalSlice); i++ {
-			typeCasteSlice[i] = make([]float64, len(originalSlice[i]))
-			for j := 0; j < len(originalSlice[i]); j++ {
-				typeCasteSlice[i][j] = float64(originalSlice[i][j])
-			}
-		}
-		curve.castedData = typeCasteSlice
+// BringToFront moves a PointGroup to the last position of the drawing
+// order, so it's drawn on top of every other PointGroup.
+func (plot *Plot) BringToFront(name string) error {
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	return plot.movePointGroup(name, len(plot.order)-1)
+}
 
-		if plot.dimensions == 2 {
-			plot.plotXY(curve)
-		} else {
-			plot.plotXYZ(curve)
-		}
-		plot.PointGroup[name] = curve
+// SendToBack moves a PointGroup to the first position of the drawing
+// order, so every other PointGroup is drawn on top of it.
+func (plot *Plot) SendToBack(name string) error {
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	return plot.movePointGroup(name, 0)
+}
 
-	case []float64:
-		curve.castedData = data.([]float64)
-		plot.plotX(curve)
-		plot.PointGroup[name] = curve
-	case []float32:
-		originalSlice := data.([]float32)
-		typeCasteSlice := make([]float64, len(originalSlice))
-		for i := 0; i < len(originalSlice); i++ {
-			typeCasteSlice[i] = float64(originalSlice[i])
-		}
-		curve.castedData = typeCasteSlice
-		plot.plotX(curve)
-		plot.PointGroup[name] = curve
-	case []int:
-		originalSlice := data.([]int)
-		typeCasteSlice := make([]float64, len(originalSlice))
-		for i := 0; i < len(originalSlice); i++ {
-			typeCasteSlice[i] = float64(originalSlice[i])
-		}
-		curve.castedData = typeCasteSlice
-		plot.plotX(curve)
-		plot.PointGroup[name] = curve
-	case []int8:
-		originalSlice := data.([]int8)
-		typeCasteSlice := make([]float64, len(originalSlice))
-		for i := 0; i < len(originalSlice); i++ {
-			typeCasteSlice[i] = float64(originalSlice[i])
-		}
-		curve.castedData = typeCasteSlice
-		plot.plotX(curve)
-		plot.PointGroup[name] = curve
-	case []int16:
-		originalSlice := data.([]int16)
-		typeCasteSlice := make([]float64, len(originalSlice))
-		for i := 0; i < len(originalSlice); i++ {
-			typeCasteSlice[i] = float64(originalSlice[i])
-		}
-		curve.castedData = typeCasteSlice
-		plot.plotX(curve)
-		plot.PointGroup[name] = curve
-	case []int32:
-		originalSlice := data.([]int32)
-		typeCasteSlice := make([]float64, len(originalSlice))
-		for i := 0; i < len(originalSlice); i++ {
-			typeCasteSlice[i] = float64(originalSlice[i])
-		}
-		curve.castedData = typeCasteSlice
-		plot.plotX(curve)
-		plot.PointGroup[name] = curve
-	case []int64:
-		originalSlice := data.([]int64)
-		typeCasteSlice := make([]float64, len(originalSlice))
-		for i := 0; i < len(originalSlice); i++ {
-			typeCasteSlice[i] = float64(originalSlice[i])
-		}
-		curve.castedData = typeCasteSlice
-		plot.plotX(curve)
-		plot.PointGroup[name] = curve
-	default:
-		return &gnuplotError{fmt.Sprintf("invalid number of dims ")}
+func (plot *Plot) movePointGroup(name string, index int) error {
+	from := plot.index(name)
+	if from < 0 {
+		return &GnuplotError{Msg: fmt.Sprintf("A curve with name %s does not exist.", name)}
+	}
+	if index < 0 || index >= len(plot.order) {
+		return &GnuplotError{Msg: fmt.Sprintf("invalid index '%v' for %v PointGroups", index, len(plot.order))}
+	}
+	if index == from {
+		return nil
+	}
+	order := append(plot.order[:from:from], plot.order[from+1:]...)
+	order = append(order[:index], append([]string{name}, order[index:]...)...)
+	plot.order = order
+	return plot.redraw()
+}
+
+// AddPointGroup function adds a group of points to a plot.
+// data is a slice of numbers, drawn against their index, or a slice of as
+// many slices of numbers as the plot has dimensions, one per coordinate.
+// Any numeric type is accepted, including unsigned and defined types like
+// type Celsius float64. AddSeries, AddXY and AddXYZ do the same with
+// type-checked arguments.
+//
+// Usage
+//
+//	dimensions := 2
+//	persist := false
+//	debug := false
+//	plot, _ := glot.NewPlot(dimensions, persist, debug)
+//	plot.AddPointGroup("Sample1", "points", []int32{51, 8, 4, 11})
+//	plot.AddPointGroup("Sample2", glot.StyleLines, []int32{1, 2, 4, 11}, glot.SeriesOptions{Color: "red", LineWidth: 2})
+//	plot.SavePlot("1.png")
+//
+// opts holds at most one SeriesOptions. style used to be a string: string
+// constants like "points" are still accepted as they are, but a string
+// variable has to be converted, as in glot.Style(s).
+func (plot *Plot) AddPointGroup(name string, style Style, data interface{}, opts ...SeriesOptions) (err error) {
+	columns, nested, err := dataColumns(data)
+	if err != nil {
+		return err
+	}
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	return plot.addPointGroup(name, style, data, columns, nested, opts)
+}
+
+// addPointGroup adds a PointGroup holding columns to the plot. nested
+// tells whether the columns were given as a slice of slices, which must
+// then have one slice per dimension of the plot.
+func (plot *Plot) addPointGroup(name string, style Style, data interface{}, columns [][]float64, nested bool, opts []SeriesOptions) (err error) {
+	_, exists := plot.pointGroups[name]
+	if exists {
+		return &GnuplotError{Kind: ErrDuplicateGroup, Msg: fmt.Sprintf("A PointGroup with the name %s  already exists, please use another name of the curve or remove this curve before using another one with the same name.", name)}
+	}
+	if nested && len(columns) != plot.dimensions {
+		return &GnuplotError{Kind: ErrDimensionMismatch, Msg: fmt.Sprintf("The dimensions of this PointGroup are not compatible with the dimensions of the plot.\nIf you want to make a 2-d curve you must specify a 2-d plot.")}
+	}
+	options, err := seriesOptions(opts)
+	if err != nil {
+		return err
+	}
+	if err := plot.useAxes(options.Axes); err != nil {
+		return err
+	}
 
+	resolved, ok := style.resolve()
+	if !ok {
+		resolved = defaultStyle
+	}
+	curve := &PointGroup{name: name, dimensions: plot.dimensions, style: resolved, data: data, options: options, set: true}
+	if times, ok := data.([]time.Time); ok {
+		curve.times = times // The x coordinates of a time series.
 	}
-	if discovered == 0 {
-		fmt.Printf("** style '%v' not in allowed list %v\n", style, allowed)
-		fmt.Printf("** default to 'points'\n")
-		err = &gnuplotError{fmt.Sprintf("invalid style '%s'", style)}
+	if len(columns) == 1 {
+		curve.castedData = columns[0]
+	} else {
+		curve.castedData = columns
+	}
+	nplots := plot.nplots
+	if err = plot.plotGroup(curve); err != nil {
+		// A PointGroup gnuplot can't draw would make every later redraw
+		// fail, so it's dropped along with its data.
+		plot.nplots = nplots
+		plot.releaseData(curve)
+		return err
+	}
+	plot.pointGroups[name] = curve
+	plot.order = append(plot.order, name)
+	if !ok {
+		plot.warn("unknown style, defaulting to points", "style", style)
+		err = &GnuplotError{Kind: ErrUnknownStyle, Msg: fmt.Sprintf("invalid style '%s'", style)}
 	}
 	return err
 }
@@ -274,19 +221,43 @@ func (plot *Plot) AddPointGroup(name string, style string, data interface{}) (er
 // This way you can remove a pointgroup if it's un-necessary.
 //
 // Usage
-//  dimensions := 3
-//  persist := false
-//  debug := false
-//  plot, _ := glot.NewPlot(dimensions, persist, debug)
-//  plot.AddPointGroup("Sample1", "points", []int32{51, 8, 4, 11})
-//  plot.AddPointGroup("Sample2", "points", []int32{1, 2, 4, 11})
-//  plot.RemovePointGroup("Sample1")
-func (plot *Plot) RemovePointGroup(name string) {
-	delete(plot.PointGroup, name)
-	plot.cleanplot()
-	for _, pointGroup := range plot.PointGroup {
-		plot.plotX(pointGroup)
+//
+//	dimensions := 3
+//	persist := false
+//	debug := false
+//	plot, _ := glot.NewPlot(dimensions, persist, debug)
+//	plot.AddPointGroup("Sample1", "points", []int32{51, 8, 4, 11})
+//	plot.AddPointGroup("Sample2", "points", []int32{1, 2, 4, 11})
+//	plot.RemovePointGroup("Sample1")
+//
+// The error is the one met while drawing the remaining point groups, or
+// clearing the plot if none remains.
+func (plot *Plot) RemovePointGroup(name string) error {
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	return plot.removePointGroup(name)
+}
+
+// removePointGroup removes a PointGroup and draws the remaining ones
+// again, or clears the plot if none remains.
+func (plot *Plot) removePointGroup(name string) error {
+	pointGroup, exists := plot.pointGroups[name]
+	if !exists {
+		return nil
+	}
+	err := plot.releaseData(pointGroup)
+	if i := plot.index(name); i >= 0 {
+		plot.order = append(plot.order[:i], plot.order[i+1:]...)
+	}
+	delete(plot.pointGroups, name)
+	delete(plot.streams, name)
+	if rerr := plot.redraw(); err == nil {
+		err = rerr
 	}
+	if err == nil && plot.nplots == 0 {
+		err = plot.cmd(context.Background(), "clear")
+	}
+	return err
 }
 
 // ResetPointGroupStyle helps to reset the style of a particular point group in a plot.
@@ -294,19 +265,37 @@ func (plot *Plot) RemovePointGroup(name string) {
 // And dynamically change the plots.
 //
 // Usage
-//  dimensions := 2
-//  persist := false
-//  debug := false
-//  plot, _ := glot.NewPlot(dimensions, persist, debug)
-//  plot.AddPointGroup("Sample1", "points", []int32{51, 8, 4, 11})
-//  plot.ResetPointGroupStyle("Sample1", "points")
-func (plot *Plot) ResetPointGroupStyle(name string, style string) (err error) {
-	pointGroup, exists := plot.PointGroup[name]
+//
+//	dimensions := 2
+//	persist := false
+//	debug := false
+//	plot, _ := glot.NewPlot(dimensions, persist, debug)
+//	plot.AddPointGroup("Sample1", "points", []int32{51, 8, 4, 11})
+//	plot.ResetPointGroupStyle("Sample1", glot.StyleLines, glot.SeriesOptions{DashType: 2})
+//
+// The options of the PointGroup are only changed if opts, which holds at
+// most one SeriesOptions, isn't empty.
+func (plot *Plot) ResetPointGroupStyle(name string, style Style, opts ...SeriesOptions) (err error) {
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	pointGroup, exists := plot.pointGroups[name]
 	if !exists {
-		return &gnuplotError{fmt.Sprintf("A curve with name %s does not exist.", name)}
+		return &GnuplotError{Msg: fmt.Sprintf("A curve with name %s does not exist.", name)}
 	}
-	plot.RemovePointGroup(name)
-	pointGroup.style = style
-	plot.plotX(pointGroup)
-	return err
+	resolved, ok := style.resolve()
+	if !ok {
+		return &GnuplotError{Kind: ErrUnknownStyle, Msg: fmt.Sprintf("invalid style '%s'", style)}
+	}
+	options, err := seriesOptions(opts)
+	if err != nil {
+		return err
+	}
+	if err := plot.useAxes(options.Axes); err != nil {
+		return err
+	}
+	pointGroup.style = resolved
+	if len(opts) > 0 {
+		pointGroup.options = options
+	}
+	return plot.redraw()
 }
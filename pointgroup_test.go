@@ -1,6 +1,15 @@
 package glot
 
-import "testing"
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
 
 func TestResetPointGroupStyle(t *testing.T) {
 	dimensions := 2
@@ -13,3 +22,210 @@ func TestResetPointGroupStyle(t *testing.T) {
 		t.Error("The specified pointgroup to be reset does not exist")
 	}
 }
+
+func TestConcurrentAddPointGroup(t *testing.T) {
+	plot, _ := NewPlotWithBackend(2, NewRecorder())
+	defer plot.Close()
+	stream, err := plot.AddStream("Stream", "lines", 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := plot.StartRefresh(time.Millisecond, ""); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("Sample%d", i)
+			if err := plot.AddPointGroup(name, "lines", [][]float64{{1, 2, 3}, {4, 5, float64(i)}}); err != nil {
+				t.Error(err)
+			}
+			for j := 0; j < 10; j++ {
+				stream.Push(float64(j), float64(i))
+				time.Sleep(time.Millisecond) // Lets the refresher run.
+			}
+			plot.SetTitle(name)
+			plot.PointGroupNames()
+			plot.BringToFront(name)
+			plot.MovePointGroup(name, i%3)
+			if err := plot.WriteScript(io.Discard); err != nil {
+				t.Error(err)
+			}
+			if err := plot.AddPointGroup(name+"-tmp", "points", [][]float64{{1}, {2}}); err != nil {
+				t.Error(err)
+			}
+			if err := plot.RemovePointGroup(name + "-tmp"); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err := plot.StopRefresh(); err != nil {
+		t.Fatal(err)
+	}
+	if names := plot.PointGroupNames(); len(names) != 9 {
+		t.Error("Expected 8 point groups and the stream, got ", names)
+	}
+	if pointGroup, ok := plot.PointGroup("Sample3"); !ok || pointGroup.Style() != "lines" {
+		t.Error("Expected to find Sample3 drawn with lines")
+	}
+	if x, _ := stream.Points(); len(x) != 16 {
+		t.Errorf("Expected the stream to hold its last 16 points, got %d", len(x))
+	}
+}
+
+func TestPointGroupOrder(t *testing.T) {
+	recorder := NewRecorder()
+	plot, _ := NewPlotWithBackend(1, recorder)
+	for _, name := range []string{"c", "a", "d", "b"} {
+		plot.AddPointGroup(name, "lines", []float64{1, 2})
+	}
+	if names, want := plot.PointGroupNames(), []string{"c", "a", "d", "b"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected insertion order %q, got %q", want, names)
+	}
+	if err := plot.BringToFront("c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := plot.SendToBack("b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := plot.MovePointGroup("d", 1); err != nil {
+		t.Fatal(err)
+	}
+	if names, want := plot.PointGroupNames(), []string{"b", "d", "a", "c"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected order %q, got %q", want, names)
+	}
+	recorder.Reset()
+	plot.SetDeferred(true)
+	plot.RemovePointGroup("a")
+	plot.SavePlot("1.png")
+	want := `plot $data4 title "b" with lines, $data3 title "d" with lines, $data1 title "c" with lines`
+	if cmds := recorder.Commands(); len(cmds) == 0 || cmds[len(cmds)-1] != want {
+		t.Errorf("Expected the last command to be %q, got %q", want, cmds)
+	}
+	if err := plot.MovePointGroup("d", 3); err == nil {
+		t.Error("Expected an error moving a PointGroup out of range")
+	}
+	if err := plot.BringToFront("a"); err == nil {
+		t.Error("Expected an error moving a removed PointGroup")
+	}
+}
+
+func TestRemoveAndRestyle(t *testing.T) {
+	data := map[int]interface{}{
+		1: []float64{1, 2, 3},
+		2: [][]float64{{1, 2, 3}, {4, 5, 6}},
+		3: [][]float64{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}},
+	}
+	for dimensions := 1; dimensions <= 3; dimensions++ {
+		recorder := NewRecorder()
+		plot, _ := NewPlotWithBackend(dimensions, recorder)
+		cmd := "plot"
+		if dimensions == 3 {
+			cmd = "splot"
+		}
+		last := func() string {
+			cmds := recorder.Commands()
+			if len(cmds) < 2 {
+				return ""
+			}
+			return strings.Join(cmds[len(cmds)-2:], "; ")
+		}
+		plot.AddPointGroup("A", "points", data[dimensions])
+		plot.AddPointGroup("B", "points", data[dimensions])
+		plot.AddPointGroup("C", "points", data[dimensions])
+		plot.RemovePointGroup("A")
+		if want := cmd + ` $data2 title "B" with points; replot $data3 title "C" with points`; last() != want {
+			t.Errorf("%d-d: expected %q after removal, got %q", dimensions, want, recorder.Commands())
+		}
+		if err := plot.ResetPointGroupStyle("B", "lines"); err != nil {
+			t.Fatal(err)
+		}
+		if want := cmd + ` $data2 title "B" with lines; replot $data3 title "C" with points`; last() != want {
+			t.Errorf("%d-d: expected %q after restyling, got %q", dimensions, want, recorder.Commands())
+		}
+		sent := 0
+		for _, record := range recorder.Records() {
+			if record.Kind == RecordData {
+				sent++
+			}
+		}
+		if sent != 3 {
+			t.Errorf("%d-d: expected the data to be sent once per PointGroup, got %d data sets", dimensions, sent)
+		}
+		if pointGroup, _ := plot.PointGroup("B"); pointGroup.Style() != "lines" {
+			t.Errorf("%d-d: expected B to be drawn with lines, got %s", dimensions, pointGroup.Style())
+		}
+		if names := plot.PointGroupNames(); !reflect.DeepEqual(names, []string{"B", "C"}) {
+			t.Errorf("%d-d: expected B and C to remain, got %q", dimensions, names)
+		}
+		plot.RemovePointGroup("B")
+		plot.RemovePointGroup("C")
+		if cmds := recorder.Commands(); cmds[len(cmds)-1] != "clear" {
+			t.Errorf("%d-d: expected the plot to be cleared, got %q", dimensions, recorder.Commands())
+		}
+	}
+}
+
+func benchmarkAddPointGroup(b *testing.B, threshold int) {
+	plot, err := New(WithBinaryThreshold(threshold))
+	if err != nil {
+		b.Skip("gnuplot is not available:", err)
+	}
+	defer plot.Close()
+	columns := largeColumns(1000000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := plot.AddPointGroup("Trace", "dots", columns); err != nil {
+			b.Fatal(err)
+		}
+		plot.RemovePointGroup("Trace")
+	}
+}
+
+func BenchmarkAddPointGroupText(b *testing.B) {
+	benchmarkAddPointGroup(b, 1<<62)
+}
+
+func BenchmarkAddPointGroupBinary(b *testing.B) {
+	benchmarkAddPointGroup(b, 0)
+}
+
+func TestRejectedPointGroup(t *testing.T) {
+	backend := rejectingBackend{NewRecorder(), "with labels"}
+	plot, _ := NewPlotWithBackend(2, backend)
+	plot.AddPointGroup("A", StylePoints, [][]float64{{1, 2}, {3, 4}})
+	var cmdErr *CommandError
+	if err := plot.AddPointGroup("B", StyleLabels, [][]float64{{1, 2}, {3, 4}}); !errors.As(err, &cmdErr) {
+		t.Fatalf("Expected a CommandError, got %v", err)
+	}
+	if names := plot.PointGroupNames(); !reflect.DeepEqual(names, []string{"A"}) {
+		t.Errorf("Expected the rejected PointGroup to be dropped, got %q", names)
+	}
+	records := backend.Records()
+	if last := records[len(records)-1]; last.Kind != RecordRelease || last.Ref != "$data2" {
+		t.Errorf("Expected the data of the rejected PointGroup to be released, got %+v", last)
+	}
+	plot.AddPointGroup("C", StylePoints, [][]float64{{1, 2}, {3, 4}})
+	if err := plot.ResetPointGroupStyle("A", StyleLines); err != nil {
+		t.Errorf("Expected the plot to be redrawn without the rejected PointGroup, got %v", err)
+	}
+	cmds := backend.Commands()
+	if want := `replot $data3 title "C" with points`; cmds[len(cmds)-1] != want {
+		t.Errorf("Expected %q, got %q", want, cmds)
+	}
+}
+
+func TestRemovePointGroupError(t *testing.T) {
+	plot, _ := NewPlotWithBackend(1, rejectingBackend{NewRecorder(), "clear"})
+	plot.AddPointGroup("A", StylePoints, []float64{1, 2})
+	var cmdErr *CommandError
+	if err := plot.RemovePointGroup("A"); !errors.As(err, &cmdErr) || cmdErr.Cmd != "clear" {
+		t.Errorf("Expected the error clearing the plot, got %v", err)
+	}
+	if err := plot.RemovePointGroup("missing"); err != nil {
+		t.Errorf("Expected no error removing a missing PointGroup, got %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package glot
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Pool keeps a fixed number of gnuplot processes running so that plots can
+// be rendered without starting a new gnuplot process for every plot.
+// A Pool is safe for concurrent use by multiple goroutines.
+type Pool struct {
+	procs chan *plotterProcess // The processes that are not in use.
+	size  int                  // The number of processes owned by the pool.
+}
+
+// Spec describes a single plot rendered by Pool.RenderAll.
+type Spec struct {
+	Dimensions int               // Dimensions of the plot.
+	Filename   string            // The file the plot is saved to.
+	Build      func(*Plot) error // Adds the point groups and settings to the plot.
+}
+
+// NewPool starts size gnuplot processes and keeps them warm until the pool
+// is closed.
+//
+// Usage
+//
+//	pool, _ := glot.NewPool(4)
+//	defer pool.Close()
+//	plot, _ := pool.NewPlot(context.Background(), 2, false)
+//	plot.AddPointGroup("Sample1", "points", []int32{51, 8, 4, 11})
+//	plot.SavePlot("1.png")
+//	plot.Close() // Hands the gnuplot process back to the pool.
+func NewPool(size int) (*Pool, error) {
+	if size < 1 {
+		return nil, &GnuplotError{Msg: fmt.Sprintf("invalid pool size '%v'", size)}
+	}
+	if _, err := Capabilities(); err != nil {
+		return nil, err
+	}
+	pool := &Pool{procs: make(chan *plotterProcess, size), size: size}
+	for i := 0; i < size; i++ {
+		proc, err := newPlotterProc(context.Background(), defaultConfig())
+		if err == nil {
+			err = park(context.Background(), proc)
+		}
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+		pool.procs <- proc
+	}
+	return pool, nil
+}
+
+// NewPlot makes a new plot backed by one of the processes of the pool.
+// It blocks until a process is free or ctx is done. Closing the plot resets
+// the gnuplot process and hands it back to the pool.
+func (pool *Pool) NewPlot(ctx context.Context, dimensions int, debug bool) (*Plot, error) {
+	p, err := newPlot(dimensions, debug)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case proc := <-pool.procs:
+		p.backend = &pooledProcess{plotterProcess: proc, pool: pool}
+		return p, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// pooledProcess is the Backend of the plots made by a Pool.
+// Closing it hands the gnuplot process back to the pool.
+type pooledProcess struct {
+	*plotterProcess
+	pool *Pool
+}
+
+func (proc *pooledProcess) Close(ctx context.Context) error {
+	return proc.pool.release(ctx, proc.plotterProcess)
+}
+
+// release resets the state of a gnuplot process and puts it back in the
+// pool. A process which doesn't survive the reset is replaced by a new one.
+func (pool *Pool) release(ctx context.Context, proc *plotterProcess) error {
+	err := park(ctx, proc)
+	if err == nil {
+		_, err = proc.Exec(ctx, "reset")
+	}
+	for ref := range proc.blocks {
+		if err == nil {
+			err = proc.ReleaseData(ctx, ref)
+		}
+	}
+	for ref := range proc.tmpfiles {
+		if rerr := proc.ReleaseData(ctx, ref); err == nil {
+			err = rerr
+		}
+	}
+	if err != nil {
+		proc.kill()
+		fresh, ferr := newPlotterProc(context.Background(), defaultConfig())
+		if ferr == nil {
+			ferr = park(context.Background(), fresh)
+		}
+		if ferr != nil {
+			// Keep the dead process so the pool doesn't shrink, its
+			// next user will get an error from it.
+			fresh = proc
+		}
+		proc = fresh
+	}
+	pool.procs <- proc
+	return err
+}
+
+// park points the output of an idle gnuplot process to the null device,
+// which also makes gnuplot finish writing the last saved plot, and selects
+// a terminal which draws nothing. The plots made by the pool are drawn on
+// it until they are saved, rather than on a window or on the standard
+// output.
+func park(ctx context.Context, proc *plotterProcess) error {
+	for _, cmd := range []string{"set output " + Quote(os.DevNull), "set terminal unknown"} {
+		lines, err := proc.Exec(ctx, cmd)
+		if err != nil {
+			return err
+		}
+		if err := commandError(cmd, lines); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenderAll builds and saves every spec, rendering as many of them in
+// parallel as the pool has processes. The returned slice holds the error
+// for each spec, in the same order as specs.
+func (pool *Pool) RenderAll(ctx context.Context, specs []Spec) []error {
+	errs := make([]error, len(specs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < pool.size; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				errs[j] = pool.render(ctx, specs[j])
+			}
+		}()
+	}
+	for i := range specs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return errs
+}
+
+func (pool *Pool) render(ctx context.Context, spec Spec) (err error) {
+	plot, err := pool.NewPlot(ctx, spec.Dimensions, false)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := plot.CloseContext(ctx); err == nil {
+			err = cerr
+		}
+	}()
+	if spec.Build != nil {
+		if err = spec.Build(plot); err != nil {
+			return err
+		}
+	}
+	return plot.SavePlotContext(ctx, spec.Filename)
+}
+
+// Close stops every gnuplot process of the pool. Plots made by the pool
+// must be closed before the pool itself.
+func (pool *Pool) Close() (err error) {
+	for {
+		select {
+		case proc := <-pool.procs:
+			if cerr := proc.Close(context.Background()); err == nil {
+				err = cerr
+			}
+		default:
+			return err
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package glot
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPoolRenderAll(t *testing.T) {
+	pool, err := NewPool(2)
+	if err != nil {
+		t.Skip("gnuplot is not available:", err)
+	}
+	defer pool.Close()
+	dir, err := os.MkdirTemp("", "glot-pool-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var specs []Spec
+	for _, name := range []string{"a.png", "b.png", "c.png", "d.png", "e.png"} {
+		specs = append(specs, Spec{
+			Dimensions: 1,
+			Filename:   filepath.Join(dir, name),
+			Build: func(plot *Plot) error {
+				return plot.AddPointGroup("Sample", "lines", []float64{1, 4, 2})
+			},
+		})
+	}
+	for i, err := range pool.RenderAll(context.Background(), specs) {
+		if err != nil {
+			t.Errorf("Rendering %s failed: %v", specs[i].Filename, err)
+		}
+		if _, err := os.Stat(specs[i].Filename); err != nil {
+			t.Errorf("Expected %s to be saved: %v", specs[i].Filename, err)
+		}
+	}
+}
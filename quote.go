@@ -0,0 +1,57 @@
+package glot
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Quote returns s as a gnuplot string literal which gnuplot reads back as
+// s, whatever s holds. Every string glot puts in a command goes through
+// Quote, use it as well for the strings given to Cmd.
+//
+// The literal is double-quoted. Backslashes, double quotes and control
+// characters are escaped, and so are the backquotes and at signs gnuplot
+// would otherwise substitute before parsing the command, so the literal
+// can neither end the command nor run another one. NUL bytes, which
+// gnuplot strings can't hold, are dropped.
+//
+// Usage
+//
+//	plot.Cmd("set label 1 %s at 1,2", glot.Quote(userText))
+func Quote(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == 0:
+		case c == '\\' || c == '"':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case c < ' ' || c == 0x7f || c == '`' || c == '@':
+			fmt.Fprintf(&b, `\%03o`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// axisNames matches the axes, or combinations of axes, gnuplot commands
+// like "set logscale" accept.
+var axisNames = regexp.MustCompile(`^(x2|y2|x|y|z|cb|r)+$`)
+
+// checkAxis returns an error if axis isn't a valid axis name, so that it
+// can be put in a command unquoted.
+func checkAxis(axis string) error {
+	if !axisNames.MatchString(axis) {
+		return &GnuplotError{Msg: fmt.Sprintf("invalid axis '%s'", axis)}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package glot
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// unquote reads a double-quoted gnuplot string literal at the start of s
+// the way gnuplot does, and returns its value and what follows it.
+func unquote(s string) (value, rest string, ok bool) {
+	if !strings.HasPrefix(s, `"`) {
+		return "", s, false
+	}
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			return b.String(), s[i+1:], true
+		case '\\':
+			if i+1 == len(s) {
+				return "", s, false
+			}
+			i++
+			switch e := s[i]; {
+			case e == 'n':
+				b.WriteByte('\n')
+			case e == 't':
+				b.WriteByte('\t')
+			case e >= '0' && e <= '7':
+				if i+3 > len(s) {
+					return "", s, false
+				}
+				v, err := strconv.ParseUint(s[i:i+3], 8, 8)
+				if err != nil {
+					return "", s, false
+				}
+				b.WriteByte(byte(v))
+				i += 2
+			default:
+				b.WriteByte(e)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", s, false
+}
+
+// checkLiteral fails the test if the command isn't made of prefix, a
+// string literal holding want and suffix, or if gnuplot would substitute
+// anything in it before parsing it.
+func checkLiteral(t *testing.T, cmd, prefix, want, suffix string) {
+	t.Helper()
+	if strings.ContainsAny(cmd, "\n\r`@\x00") {
+		t.Fatalf("Command %q holds characters gnuplot would act on", cmd)
+	}
+	if !strings.HasPrefix(cmd, prefix) {
+		t.Fatalf("Expected command %q to start with %q", cmd, prefix)
+	}
+	value, rest, ok := unquote(strings.TrimPrefix(cmd, prefix))
+	if !ok {
+		t.Fatalf("Command %q doesn't hold a string literal", cmd)
+	}
+	if value != strings.ReplaceAll(want, "\x00", "") {
+		t.Fatalf("Expected the literal in %q to read back as %q, got %q", cmd, want, value)
+	}
+	if rest != suffix {
+		t.Fatalf("Expected %q after the literal in %q, got %q", suffix, cmd, rest)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := map[string]string{
+		"Test Results":          `"Test Results"`,
+		"O'Brien":               `"O'Brien"`,
+		`say "hi"`:              `"say \"hi\""`,
+		`C:\plots\1.png`:        `"C:\\plots\\1.png"`,
+		"a\nb":                  `"a\nb"`,
+		"`rm -rf /`":            `"\140rm -rf /\140"`,
+		"@macro":                `"\100macro"`,
+		"nul\x00byte":           `"nulbyte"`,
+		"\"; system \"rm -rf /": `"\"; system \"rm -rf /"`,
+	}
+	for s, want := range tests {
+		if got := Quote(s); got != want {
+			t.Errorf("Expected Quote(%q) to be %s, got %s", s, want, got)
+		}
+	}
+}
+
+func FuzzQuote(f *testing.F) {
+	for _, seed := range []string{"", "O'Brien", `a"b\c`, "x\ny", "`ls`", "@a", "\x00\x7f\xff", "\"; system \"ls"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		checkLiteral(t, Quote(s), "", s, "")
+	})
+}
+
+func FuzzCommandStrings(f *testing.F) {
+	for _, seed := range []string{"Sample1", "O'Brien", "a\"\nplot '<rm -rf /'", "`ls`", "\\"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		recorder := NewRecorder()
+		plot, _ := NewPlotWithBackend(1, recorder)
+		plot.SetTitle(s)
+		plot.SetXLabel(s)
+		plot.SetYLabel(s)
+		plot.SetZLabel(s)
+		if err := plot.AddPointGroup(s, "lines", []float64{1, 2}); err != nil {
+			t.Fatal(err)
+		}
+		if err := plot.SetLogscale(s, 10); err == nil && !axisNames.MatchString(s) {
+			t.Fatalf("Expected an error for the axis %q", s)
+		}
+		_, known := Style(s).resolve()
+		if err := plot.ResetPointGroupStyle(s, Style(s)); err == nil && !known {
+			t.Fatalf("Expected an error for the style %q", s)
+		} else if !known && !errors.Is(err, ErrUnknownStyle) {
+			t.Fatalf("Expected ErrUnknownStyle for the style %q, got %v", s, err)
+		}
+		cmds := recorder.Commands()
+		checkLiteral(t, cmds[0], "set title ", s, " ")
+		checkLiteral(t, cmds[1], "set xlabel ", s, "")
+		checkLiteral(t, cmds[2], "set ylabel ", s, "")
+		checkLiteral(t, cmds[3], "set zlabel ", s, "")
+		if s == "" {
+			// A PointGroup without a name has no title.
+			if cmds[4] != "plot $data1 with lines" {
+				t.Fatalf("Expected no title for a PointGroup without a name, got %q", cmds[4])
+			}
+			return
+		}
+		checkLiteral(t, cmds[4], "plot $data1 title ", s, " with lines")
+	})
+}
+
+func TestSavePlotQuotedFilename(t *testing.T) {
+	plot, err := NewPlot(1, false, false)
+	if err != nil {
+		t.Skip("gnuplot is not available:", err)
+	}
+	defer plot.Close()
+	filename := filepath.Join(t.TempDir(), `O'Brien "1".png`)
+	plot.AddPointGroup("O'Brien", "lines", []float64{1, 2, 3})
+	plot.SetTitle(`O'Brien's "plot"`)
+	if err := plot.SavePlot(filename); err != nil {
+		t.Fatal(err)
+	}
+	plot.Close()
+	if _, err := os.Stat(filename); err != nil {
+		t.Error("Expected the plot to be saved: ", err)
+	}
+}
@@ -0,0 +1,87 @@
+package glot
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// setting is a gnuplot command changing a setting of the plot.
+type setting struct {
+	key string // Identifies the setting, a later setting with the same key replaces this one.
+	cmd string // The command applying the setting.
+}
+
+// set sends a setting to gnuplot and remembers it for WriteScript.
+func (plot *Plot) set(key, cmd string) error {
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	return plot.applySetting(key, cmd)
+}
+
+// applySetting is like set for callers which already hold plot.mu.
+func (plot *Plot) applySetting(key, cmd string) error {
+	if err := plot.cmd(context.Background(), cmd); err != nil {
+		return err
+	}
+	for i := range plot.settings {
+		if plot.settings[i].key == key {
+			plot.settings[i].cmd = cmd
+			return nil
+		}
+	}
+	plot.settings = append(plot.settings, setting{key: key, cmd: cmd})
+	return nil
+}
+
+// WriteScript writes a standalone gnuplot script which draws the plot.
+// The script holds every setting applied with the Set methods of the plot
+// followed by the data of each PointGroup, inlined as a datablock, and the
+// plot command drawing them. Commands sent with Cmd are not part of the
+// script.
+//
+// Usage
+//
+//	plot, _ := glot.NewPlot(2, false, false)
+//	plot.AddPointGroup("Sample1", "points", [][]float64{{1, 2, 3}, {4, 1, 7}})
+//	plot.SetTitle("Test Results")
+//	f, _ := os.Create("plot.gp")
+//	plot.WriteScript(f) // gnuplot -persist plot.gp draws the same plot.
+func (plot *Plot) WriteScript(w io.Writer) error {
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# gnuplot script written by glot\n")
+	for _, s := range plot.settings {
+		fmt.Fprintf(bw, "%s\n", s.cmd)
+	}
+	var clauses []string
+	for _, name := range plot.names() {
+		pointGroup := plot.pointGroups[name]
+		columns := pointGroup.columns()
+		if stream, ok := plot.streams[name]; ok {
+			// The points of a stream as they are now, not as they were
+			// when the plot was last refreshed.
+			x, y := stream.Points()
+			columns = [][]float64{x, y}
+		}
+		if npoints(columns) == 0 {
+			continue // gnuplot can't plot an empty datablock.
+		}
+		ref := fmt.Sprintf("$DATA%d", len(clauses)+1)
+		fmt.Fprintf(bw, "%s << EOD\n", ref)
+		writeColumns(bw, columns)
+		fmt.Fprintf(bw, "EOD\n")
+		clauses = append(clauses, plotClause(ref, pointGroup))
+	}
+	if len(clauses) > 0 {
+		cmd := "plot"
+		if plot.dimensions == 3 {
+			cmd = "splot"
+		}
+		fmt.Fprintf(bw, "%s %s\n", cmd, strings.Join(clauses, ", "))
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,62 @@
+package glot
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteScript(t *testing.T) {
+	plot, err := NewPlotWithBackend(2, NewRecorder())
+	if err != nil {
+		t.Fatal(err)
+	}
+	plot.SetXrange(0, 5)
+	plot.AddPointGroup("Sample1", "points", [][]float64{{1, 2, 3}, {4, 1, 7}})
+	plot.AddPointGroup("Sample2", "lines", [][]float64{{1, 2}, {2, 2}})
+	plot.SetTitle("Test Results")
+	plot.SetXrange(-1, 4)
+	var buf bytes.Buffer
+	if err := plot.WriteScript(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `# gnuplot script written by glot
+set xrange [-1:4]
+set title "Test Results" 
+$DATA1 << EOD
+1 4
+2 1
+3 7
+EOD
+$DATA2 << EOD
+1 2
+2 2
+EOD
+plot $DATA1 title "Sample1" with points, $DATA2 title "Sample2" with lines
+`
+	if buf.String() != want {
+		t.Errorf("Expected script:\n%s\ngot:\n%s", want, buf.String())
+	}
+}
+
+func TestWriteScriptStreams(t *testing.T) {
+	plot, _ := NewPlotWithBackend(2, NewRecorder())
+	plot.AddStream("Empty", "lines", 5)
+	stream, _ := plot.AddStream("Latency", "lines", 5)
+	stream.Push(1, 10)
+	plot.Refresh("")
+	stream.Push(2, 20)
+	var buf bytes.Buffer
+	if err := plot.WriteScript(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `# gnuplot script written by glot
+$DATA1 << EOD
+1 10
+2 20
+EOD
+plot $DATA1 title "Latency" with lines
+`
+	if buf.String() != want {
+		t.Errorf("Expected script:\n%s\ngot:\n%s", want, buf.String())
+	}
+}
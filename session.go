@@ -0,0 +1,424 @@
+package glot
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// gSessionHeader is the first line of every session transcript.
+const gSessionHeader = "# glot session 1"
+
+// session is a Backend which writes a transcript of every call made to
+// another Backend, so that the calls can be made again by Replay.
+//
+// A transcript is a text file holding one entry per call:
+//
+//	terminal "pngcairo size 800,600"
+//	cmd "set title \"Test\""
+//	data "$glot1" 2 3
+//	1 4
+//	2 5
+//	3 6
+//	release "$glot1"
+//	render "png" "1.png"
+//	render "png" "2.png" "plot $glot2 title \"Test\" with lines"
+//
+// Strings are quoted with Go syntax, data entries are followed by one
+// line per point. The terminal entry is only written for the plots made
+// with the WithTerminal option. A render entry holds the plot command
+// when the plot is drawn and saved at once, like a deferred plot, and an
+// empty file name when the plot is rendered to a writer.
+type session struct {
+	Backend
+	file *os.File
+	w    *bufio.Writer
+}
+
+// newSession starts writing a transcript of the calls made to backend to
+// the file at path.
+func newSession(backend Backend, path string) (*session, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &session{Backend: backend, file: file, w: bufio.NewWriter(file)}
+	err = s.write("%s\n", gSessionHeader)
+	if terminal := terminalOf(backend); err == nil && terminal != "" {
+		err = s.write("terminal %q\n", terminal)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// terminalOf returns the terminal the gnuplot process behind backend saves
+// plots with if it was chosen with WithTerminal, or an empty string.
+func terminalOf(backend Backend) string {
+	switch proc := backend.(type) {
+	case *plotterProcess:
+		return proc.terminal
+	case *pooledProcess:
+		return proc.terminal
+	}
+	return ""
+}
+
+// write appends an entry to the transcript. Entries are flushed right
+// away so the transcript is complete even if the program crashes.
+func (s *session) write(format string, a ...interface{}) error {
+	fmt.Fprintf(s.w, format, a...)
+	return s.w.Flush()
+}
+
+func (s *session) Exec(ctx context.Context, cmd string) ([]string, error) {
+	if err := s.write("cmd %q\n", cmd); err != nil {
+		return nil, err
+	}
+	return s.Backend.Exec(ctx, cmd)
+}
+
+func (s *session) SendData(ctx context.Context, columns [][]float64) (string, error) {
+	ref, err := s.Backend.SendData(ctx, columns)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(s.w, "data %q %d %d\n", ref, len(columns), npoints(columns))
+	writeColumns(s.w, columns)
+	return ref, s.w.Flush()
+}
+
+func (s *session) ReleaseData(ctx context.Context, ref string) error {
+	if err := s.write("release %q\n", ref); err != nil {
+		return err
+	}
+	return s.Backend.ReleaseData(ctx, ref)
+}
+
+func (s *session) Render(ctx context.Context, format, filename string) error {
+	if err := s.write("render %q %q\n", format, filename); err != nil {
+		return err
+	}
+	return s.Backend.Render(ctx, format, filename)
+}
+
+func (s *session) RenderTo(ctx context.Context, format string, w io.Writer) error {
+	if err := s.write("render %q %q\n", format, ""); err != nil {
+		return err
+	}
+	return s.Backend.RenderTo(ctx, format, w)
+}
+
+func (s *session) renderPlot(ctx context.Context, format, filename, plotCmd string) error {
+	if err := s.write("render %q %q %q\n", format, filename, plotCmd); err != nil {
+		return err
+	}
+	return renderWith(ctx, s.Backend, format, filename, nil, plotCmd)
+}
+
+func (s *session) renderPlotTo(ctx context.Context, format string, w io.Writer, plotCmd string) error {
+	if err := s.write("render %q %q %q\n", format, "", plotCmd); err != nil {
+		return err
+	}
+	return renderWith(ctx, s.Backend, format, "", w, plotCmd)
+}
+
+// renderWith draws the plot with plotCmd and saves it to filename, or
+// writes it to w if w isn't nil. The backends which aren't plotRenderers
+// run plotCmd and then render the plot.
+func renderWith(ctx context.Context, backend Backend, format, filename string, w io.Writer, plotCmd string) error {
+	if renderer, ok := backend.(plotRenderer); ok {
+		if w != nil {
+			return renderer.renderPlotTo(ctx, format, w, plotCmd)
+		}
+		return renderer.renderPlot(ctx, format, filename, plotCmd)
+	}
+	lines, err := backend.Exec(ctx, plotCmd)
+	if err == nil {
+		err = commandError(plotCmd, lines)
+	}
+	if err != nil {
+		return err
+	}
+	if w != nil {
+		return backend.RenderTo(ctx, format, w)
+	}
+	return backend.Render(ctx, format, filename)
+}
+
+func (s *session) checkFormat(format string) error {
+	if checker, ok := s.Backend.(formatChecker); ok {
+		return checker.checkFormat(format)
+	}
+	return nil
+}
+
+// stop closes the transcript and returns the recorded backend.
+func (s *session) stop() (Backend, error) {
+	err := s.w.Flush()
+	if cerr := s.file.Close(); err == nil {
+		err = cerr
+	}
+	return s.Backend, err
+}
+
+func (s *session) Close(ctx context.Context) error {
+	backend, err := s.stop()
+	if cerr := backend.Close(ctx); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// RecordSession writes a transcript of everything the plot sends to
+// gnuplot from now on, commands and data, to the file at path, until
+// StopSession is called or the plot is closed. Replay sends the
+// transcript to a fresh gnuplot to reproduce the plot.
+// Use the WithSession option of New to record a plot from the start.
+//
+// Usage
+//
+//	plot, _ := glot.NewPlot(2, false, false)
+//	plot.RecordSession("chart.session")
+//	plot.AddPointGroup("Sample1", "points", [][]float64{{1, 2, 3}, {4, 1, 7}})
+//	plot.SavePlot("chart.png")
+//	plot.StopSession()
+//	// Later, with another gnuplot, saves chart.png again.
+//	glot.Replay("chart.session")
+func (plot *Plot) RecordSession(path string) error {
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	return plot.recordSession(path)
+}
+
+func (plot *Plot) recordSession(path string) error {
+	if plot.backend == nil {
+		return &GnuplotError{Msg: "the plot is closed"}
+	}
+	if _, ok := plot.backend.(*session); ok {
+		return &GnuplotError{Msg: "the session of the plot is already being recorded"}
+	}
+	s, err := newSession(plot.backend, path)
+	if err != nil {
+		return err
+	}
+	plot.backend = s
+	return nil
+}
+
+// StopSession stops the recording started by RecordSession and closes the
+// transcript.
+func (plot *Plot) StopSession() error {
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	s, ok := plot.backend.(*session)
+	if !ok {
+		return nil
+	}
+	backend, err := s.stop()
+	plot.backend = backend
+	return err
+}
+
+// Replay sends the session transcript at path, written by RecordSession,
+// to a fresh gnuplot process configured by opts. Plots saved during the
+// session are saved again to the same files, with the terminal of the
+// recorded plot unless opts holds WithTerminal.
+// Like the recorded session, Replay carries on after the commands gnuplot
+// rejects, and returns their errors once the whole transcript is replayed.
+func Replay(path string, opts ...Option) error {
+	return ReplayContext(context.Background(), path, opts...)
+}
+
+// ReplayContext is like Replay but gives up and kills gnuplot as soon as
+// ctx is done.
+func ReplayContext(ctx context.Context, path string, opts ...Option) (err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if _, err := capabilities(cfg.path); err != nil {
+		return err
+	}
+	proc, err := newPlotterProc(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := proc.Close(ctx); err == nil {
+			err = cerr
+		}
+	}()
+	return replay(ctx, bufio.NewReader(file), proc)
+}
+
+// replay makes the calls of a transcript to backend. The data sets get
+// new references, which are substituted to the recorded ones in the
+// commands. The errors reported by gnuplot are collected rather than
+// returned right away, any other error ends the replay.
+func replay(ctx context.Context, r *bufio.Reader, backend Backend) error {
+	refs := make(map[string]string) // Recorded references to the new ones.
+	var replacer *strings.Replacer
+	var errs []error
+	rejected := func(err error) bool {
+		var cmdErr *CommandError
+		if errors.As(err, &cmdErr) {
+			errs = append(errs, err)
+			return true
+		}
+		return false
+	}
+	line, err := readLine(r)
+	if err != nil || line != gSessionHeader {
+		return &GnuplotError{Msg: "not a glot session transcript"}
+	}
+	for n := 2; ; n++ {
+		line, err := readLine(r)
+		if err == io.EOF {
+			return errors.Join(errs...)
+		}
+		if err != nil {
+			return err
+		}
+		kind, rest, _ := strings.Cut(line, " ")
+		switch kind {
+		case "cmd":
+			cmd, err := strconv.Unquote(rest)
+			if err != nil {
+				return sessionError(n, err)
+			}
+			if replacer != nil {
+				cmd = replacer.Replace(cmd)
+			}
+			lines, err := backend.Exec(ctx, cmd)
+			if err != nil && !rejected(err) {
+				return err
+			}
+			rejected(commandError(cmd, lines))
+		case "data":
+			var ref string
+			var ncolumns, npoints int
+			if _, err := fmt.Sscanf(rest, "%q %d %d", &ref, &ncolumns, &npoints); err != nil {
+				return sessionError(n, err)
+			}
+			columns := make([][]float64, ncolumns)
+			for i := 0; i < npoints; i++ {
+				n++
+				line, err := readLine(r)
+				if err != nil {
+					return sessionError(n, err)
+				}
+				fields := strings.Fields(line)
+				if len(fields) != ncolumns {
+					return sessionError(n, fmt.Errorf("expected %d values, got %d", ncolumns, len(fields)))
+				}
+				for j, field := range fields {
+					v, err := strconv.ParseFloat(field, 64)
+					if err != nil {
+						return sessionError(n, err)
+					}
+					columns[j] = append(columns[j], v)
+				}
+			}
+			newRef, err := backend.SendData(ctx, columns)
+			if err != nil {
+				if rejected(err) {
+					continue
+				}
+				return err
+			}
+			refs[ref] = newRef
+			replacer = refReplacer(refs)
+		case "release":
+			ref, err := strconv.Unquote(rest)
+			if err != nil {
+				return sessionError(n, err)
+			}
+			if newRef, ok := refs[ref]; ok {
+				delete(refs, ref)
+				replacer = refReplacer(refs)
+				if err := backend.ReleaseData(ctx, newRef); err != nil {
+					return err
+				}
+			}
+		case "render":
+			var format, filename, plotCmd string
+			if _, err := fmt.Sscanf(rest, "%q %q %q", &format, &filename, &plotCmd); err != nil {
+				plotCmd = ""
+				if _, err := fmt.Sscanf(rest, "%q %q", &format, &filename); err != nil {
+					return sessionError(n, err)
+				}
+			}
+			var w io.Writer
+			if filename == "" {
+				w = io.Discard
+			}
+			switch {
+			case plotCmd != "":
+				if replacer != nil {
+					plotCmd = replacer.Replace(plotCmd)
+				}
+				err = renderWith(ctx, backend, format, filename, w, plotCmd)
+			case w != nil:
+				err = backend.RenderTo(ctx, format, w)
+			default:
+				err = backend.Render(ctx, format, filename)
+			}
+			if err != nil && !rejected(err) {
+				return err
+			}
+		case "terminal":
+			terminal, err := strconv.Unquote(rest)
+			if err != nil {
+				return sessionError(n, err)
+			}
+			if proc, ok := backend.(*plotterProcess); ok && proc.terminal == "" {
+				proc.terminal = terminal
+			}
+		default:
+			return sessionError(n, fmt.Errorf("unknown entry %q", kind))
+		}
+	}
+}
+
+// refReplacer substitutes the new references of the data sets to the
+// recorded ones. Longer references are tried first so that $glot1 doesn't
+// match the start of $glot10.
+func refReplacer(refs map[string]string) *strings.Replacer {
+	old := make([]string, 0, len(refs))
+	for ref := range refs {
+		old = append(old, ref)
+	}
+	sort.Slice(old, func(i, j int) bool { return len(old[i]) > len(old[j]) })
+	pairs := make([]string, 0, 2*len(old))
+	for _, ref := range old {
+		pairs = append(pairs, ref, refs[ref])
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// readLine reads a line without its end of line.
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimSuffix(line, "\n"), err
+}
+
+func sessionError(line int, err error) error {
+	return &GnuplotError{Msg: fmt.Sprintf("invalid session transcript at line %d", line), Err: err}
+}
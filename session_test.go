@@ -0,0 +1,180 @@
+package glot
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReplaySession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.session")
+	recorder := NewRecorder()
+	plot, _ := NewPlotWithBackend(1, recorder)
+	if err := plot.RecordSession(path); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 11; i++ {
+		plot.AddPointGroup(fmt.Sprint("Sample", i), "lines", []float64{float64(i), 0.1, -2.5e-7})
+	}
+	plot.RemovePointGroup("Sample1")
+	plot.SetTitle("Test Results")
+	plot.SavePlot("1.png")
+	if err := plot.StopSession(); err != nil {
+		t.Fatal(err)
+	}
+	plot.SetTitle("Not recorded")
+
+	// Data already sent shifts the references of the replayed data.
+	replayed := NewRecorder()
+	replayed.SendData(context.Background(), [][]float64{{0}})
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := replay(context.Background(), bufio.NewReader(file), replayed); err != nil {
+		t.Fatal(err)
+	}
+	recorded := recorder.Commands()
+	want := recorded[:len(recorded)-1] // Without the command sent after StopSession.
+	var shifted []string
+	for _, cmd := range want {
+		refs := make(map[string]string)
+		for i := 1; i <= 22; i++ {
+			refs[fmt.Sprintf("$data%d", i)] = fmt.Sprintf("$data%d", i+1)
+		}
+		shifted = append(shifted, refReplacer(refs).Replace(cmd))
+	}
+	if got := replayed.Commands(); !reflect.DeepEqual(got, shifted) {
+		t.Errorf("Expected the replayed commands\n%q\ngot\n%q", shifted, got)
+	}
+	records := replayed.Records()
+	if last := records[len(records)-1]; last.Kind != RecordRender || last.Filename != "1.png" {
+		t.Errorf("Expected the plot to be saved again, got %+v", last)
+	}
+	if data := records[1].Data; !reflect.DeepEqual(data, [][]float64{{1, 0.1, -2.5e-7}}) {
+		t.Errorf("Expected the data to be replayed exactly, got %v", data)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.session")
+	output := filepath.Join(dir, "1.png")
+	plot, err := New(WithDimensions(2), WithSession(path))
+	if err != nil {
+		t.Skip("gnuplot is not available:", err)
+	}
+	plot.AddPointGroup("Sample1", "lines", [][]float64{{1, 2, 3}, {4, 1, 7}})
+	plot.SetTitle("O'Brien")
+	if err := plot.SavePlot(output); err != nil {
+		t.Fatal(err)
+	}
+	plot.Close()
+	os.Remove(output)
+	if err := Replay(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(output); err != nil {
+		t.Error("Expected Replay to save the plot again: ", err)
+	}
+	if err := Replay(filepath.Join(dir, "missing.session")); err == nil {
+		t.Error("Expected an error replaying a missing transcript")
+	}
+}
+
+// rejectingBackend is a Recorder on which gnuplot rejects the commands
+// holding rejected.
+type rejectingBackend struct {
+	*Recorder
+	rejected string
+}
+
+func (b rejectingBackend) Exec(ctx context.Context, cmd string) ([]string, error) {
+	b.Recorder.Exec(ctx, cmd)
+	if strings.Contains(cmd, b.rejected) {
+		return []string{"         line 0: unrecognized option - see 'help set'."}, nil
+	}
+	return nil, nil
+}
+
+func TestReplayCarriesOnAfterErrors(t *testing.T) {
+	transcript := gSessionHeader + "\n" +
+		`cmd "set bogusoption"` + "\n" +
+		`data "$data1" 1 2` + "\n1\n2\n" +
+		`cmd "plot $data1 title \"A\" with lines"` + "\n" +
+		`render "png" "1.png"` + "\n"
+	replayed := rejectingBackend{NewRecorder(), "set bogus"}
+	err := replay(context.Background(), bufio.NewReader(strings.NewReader(transcript)), replayed)
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.Cmd != "set bogusoption" {
+		t.Errorf("Expected the rejected command to be reported, got %v", err)
+	}
+	records := replayed.Records()
+	if last := records[len(records)-1]; last.Kind != RecordRender || last.Filename != "1.png" {
+		t.Errorf("Expected the plot to be saved despite the error, got %+v", last)
+	}
+}
+
+func TestSessionTerminal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.session")
+	s, err := newSession(&plotterProcess{terminal: "pngcairo size 800,600"}, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.stop()
+	transcript, _ := os.ReadFile(path)
+	want := gSessionHeader + "\n" + `terminal "pngcairo size 800,600"` + "\n"
+	if string(transcript) != want {
+		t.Errorf("Expected the terminal to be recorded, got %q", transcript)
+	}
+	proc := &plotterProcess{}
+	if err := replay(context.Background(), bufio.NewReader(strings.NewReader(want)), proc); err != nil {
+		t.Fatal(err)
+	}
+	if proc.terminal != "pngcairo size 800,600" {
+		t.Errorf("Expected the recorded terminal to be replayed, got %q", proc.terminal)
+	}
+}
+
+func TestSessionDeferred(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.session")
+	recorder := terminalRecorder{NewRecorder()}
+	plot, _ := NewPlotWithBackend(1, recorder)
+	plot.SetDeferred(true)
+	if err := plot.RecordSession(path); err != nil {
+		t.Fatal(err)
+	}
+	plot.AddPointGroup("a", "lines", []float64{1, 2})
+	if err := plot.SavePlot("1.png"); err != nil {
+		t.Fatal(err)
+	}
+	plot.StopSession()
+	want := []string{"set terminal png", `set output "1.png"`, `plot $data1 title "a" with lines`}
+	if got := recorder.Commands(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the deferred plot to be drawn once, got %q", got)
+	}
+	transcript, _ := os.ReadFile(path)
+	if entry := `render "png" "1.png" "plot $data1 title \"a\" with lines"`; !strings.Contains(string(transcript), entry) {
+		t.Errorf("Expected the plot command in the transcript, got %q", transcript)
+	}
+
+	replayed := terminalRecorder{NewRecorder()}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := replay(context.Background(), bufio.NewReader(file), replayed); err != nil {
+		t.Fatal(err)
+	}
+	if got := replayed.Commands(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected the replayed commands %q, got %q", want, got)
+	}
+}
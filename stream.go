@@ -0,0 +1,204 @@
+package glot
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Stream is a 2-d PointGroup which is fed one point at a time and only
+// keeps its last points, for plots which follow a metric as it evolves.
+// Pushing points doesn't redraw the plot, the plot is redrawn by Refresh
+// or periodically after StartRefresh.
+// A Stream is safe for concurrent use by multiple goroutines.
+type Stream struct {
+	mu    sync.Mutex
+	x, y  []float64 // Ring buffers holding the points.
+	start int       // Index of the oldest point.
+	n     int       // Number of points held.
+	dirty bool      // Whether points were pushed since the last refresh.
+}
+
+// refresher redraws a plot periodically.
+type refresher struct {
+	stop chan struct{}
+	done chan struct{}
+	err  error // The last error met while refreshing.
+}
+
+// AddStream adds a Stream holding at most capacity points to a 2-d plot.
+//
+// Usage
+//
+//	plot, _ := glot.NewPlot(2, true, false)
+//	stream, _ := plot.AddStream("Latency", "lines", 100)
+//	plot.StartRefresh(time.Second, "")
+//	for t := range ticker.C {
+//		stream.Push(float64(t.Unix()), measure())
+//	}
+//
+// opts holds at most one SeriesOptions.
+func (plot *Plot) AddStream(name string, style Style, capacity int, opts ...SeriesOptions) (*Stream, error) {
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	if plot.dimensions != 2 {
+		return nil, &GnuplotError{Kind: ErrDimensionMismatch, Msg: fmt.Sprintf("streams can only be added to 2-d plots, this plot has %v dimensions", plot.dimensions)}
+	}
+	if capacity < 1 {
+		return nil, &GnuplotError{Msg: fmt.Sprintf("invalid stream capacity '%v'", capacity)}
+	}
+	if _, exists := plot.pointGroups[name]; exists {
+		return nil, &GnuplotError{Kind: ErrDuplicateGroup, Msg: fmt.Sprintf("A PointGroup with the name %s  already exists, please use another name of the curve or remove this curve before using another one with the same name.", name)}
+	}
+	if style == "" {
+		style = defaultStyle
+	}
+	style, ok := style.resolve()
+	if !ok {
+		return nil, &GnuplotError{Kind: ErrUnknownStyle, Msg: fmt.Sprintf("invalid style '%s'", style)}
+	}
+	options, err := seriesOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := plot.useAxes(options.Axes); err != nil {
+		return nil, err
+	}
+	stream := &Stream{x: make([]float64, capacity), y: make([]float64, capacity)}
+	plot.pointGroups[name] = &PointGroup{name: name, dimensions: 2, style: style, options: options,
+		castedData: [][]float64{{}, {}}, set: true}
+	plot.streams[name] = stream
+	plot.order = append(plot.order, name)
+	return stream, nil
+}
+
+// Push adds a point to the stream, dropping the oldest point if the
+// stream is full.
+func (stream *Stream) Push(x, y float64) {
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	capacity := len(stream.x)
+	i := (stream.start + stream.n) % capacity
+	stream.x[i], stream.y[i] = x, y
+	if stream.n < capacity {
+		stream.n++
+	} else {
+		stream.start = (stream.start + 1) % capacity
+	}
+	stream.dirty = true
+}
+
+// Points returns the points held by the stream, oldest first.
+func (stream *Stream) Points() (x, y []float64) {
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	return stream.points()
+}
+
+func (stream *Stream) points() (x, y []float64) {
+	capacity := len(stream.x)
+	x = make([]float64, stream.n)
+	y = make([]float64, stream.n)
+	for i := 0; i < stream.n; i++ {
+		x[i] = stream.x[(stream.start+i)%capacity]
+		y[i] = stream.y[(stream.start+i)%capacity]
+	}
+	return x, y
+}
+
+// Refresh redraws the plot if points were pushed to any of its streams
+// since the last refresh. If filename isn't empty the plot is also saved
+// to it.
+func (plot *Plot) Refresh(filename string) error {
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	return plot.refresh(filename)
+}
+
+func (plot *Plot) refresh(filename string) error {
+	if plot.backend == nil {
+		return &GnuplotError{Msg: "the plot is closed"}
+	}
+	dirty := false
+	for name, stream := range plot.streams {
+		stream.mu.Lock()
+		var x, y []float64
+		changed := stream.dirty
+		if changed {
+			x, y = stream.points()
+			stream.dirty = false
+		}
+		stream.mu.Unlock()
+		if !changed {
+			continue
+		}
+		// Only the streams which changed are sent again by redraw.
+		pointGroup := plot.pointGroups[name]
+		if err := plot.releaseData(pointGroup); err != nil {
+			return err
+		}
+		pointGroup.castedData = [][]float64{x, y}
+		dirty = true
+	}
+	if !dirty {
+		return nil
+	}
+	if err := plot.redraw(); err != nil {
+		return err
+	}
+	if filename == "" || plot.nplots == 0 {
+		return plot.flush(context.Background())
+	}
+	return plot.render(context.Background(), plot.format, filename, nil)
+}
+
+// StartRefresh refreshes the plot every interval until StopRefresh is
+// called or the plot is closed. With an empty filename the plot is only
+// redrawn, which updates the window of a persistent plot, otherwise it's
+// also saved to filename.
+func (plot *Plot) StartRefresh(interval time.Duration, filename string) error {
+	if interval <= 0 {
+		return &GnuplotError{Msg: fmt.Sprintf("invalid refresh interval '%v'", interval)}
+	}
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	if plot.refresher != nil {
+		return &GnuplotError{Msg: "the plot is already being refreshed"}
+	}
+	r := &refresher{stop: make(chan struct{}), done: make(chan struct{})}
+	plot.refresher = r
+	go func() {
+		defer close(r.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				plot.mu.Lock()
+				if err := plot.refresh(filename); err != nil {
+					r.err = err
+				}
+				plot.mu.Unlock()
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// StopRefresh stops the periodic refresh started by StartRefresh and
+// returns the last error met while refreshing, if any.
+func (plot *Plot) StopRefresh() error {
+	plot.mu.Lock()
+	r := plot.refresher
+	plot.refresher = nil
+	plot.mu.Unlock()
+	if r == nil {
+		return nil
+	}
+	close(r.stop)
+	<-r.done
+	return r.err
+}
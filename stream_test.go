@@ -0,0 +1,76 @@
+package glot
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStream(t *testing.T) {
+	recorder := NewRecorder()
+	plot, _ := NewPlotWithBackend(2, recorder)
+	stream, err := plot.AddStream("Latency", "lines", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		stream.Push(float64(i), float64(10*i))
+	}
+	x, y := stream.Points()
+	if !reflect.DeepEqual(x, []float64{2, 3, 4}) || !reflect.DeepEqual(y, []float64{20, 30, 40}) {
+		t.Errorf("Expected the last 3 points, got %v %v", x, y)
+	}
+	if err := plot.Refresh("latency.png"); err != nil {
+		t.Fatal(err)
+	}
+	records := recorder.Records()
+	want := []Record{
+		{Kind: RecordData, Ref: "$data1", Data: [][]float64{{2, 3, 4}, {20, 30, 40}}},
+		{Kind: RecordCmd, Cmd: `plot $data1 title "Latency" with lines`},
+		{Kind: RecordRender, Format: "png", Filename: "latency.png"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("Expected records %v, got %v", want, records)
+	}
+	recorder.Reset()
+	if err := plot.Refresh(""); err != nil || len(recorder.Records()) != 0 {
+		t.Error("Expected no redraw when no point was pushed")
+	}
+
+	plot.AddPointGroup("Threshold", "lines", [][]float64{{0, 10}, {25, 25}})
+	recorder.Reset()
+	stream.Push(5, 50)
+	if err := plot.Refresh(""); err != nil {
+		t.Fatal(err)
+	}
+	want = []Record{
+		{Kind: RecordRelease, Ref: "$data1"},
+		{Kind: RecordData, Ref: "$data3", Data: [][]float64{{3, 4, 5}, {30, 40, 50}}},
+		{Kind: RecordCmd, Cmd: `plot $data3 title "Latency" with lines`},
+		{Kind: RecordCmd, Cmd: `replot $data2 title "Threshold" with lines`},
+	}
+	if records := recorder.Records(); !reflect.DeepEqual(records, want) {
+		t.Errorf("Expected only the stream to be sent again, got %v", records)
+	}
+}
+
+func TestStartRefresh(t *testing.T) {
+	recorder := NewRecorder()
+	plot, _ := NewPlotWithBackend(2, recorder)
+	stream, _ := plot.AddStream("Latency", "lines", 10)
+	if err := plot.StartRefresh(time.Millisecond, ""); err != nil {
+		t.Fatal(err)
+	}
+	stream.Push(1, 2)
+	deadline := time.Now().Add(time.Second)
+	for len(recorder.Commands()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if err := plot.StopRefresh(); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.Commands()) == 0 {
+		t.Error("Expected the plot to be redrawn after a point was pushed")
+	}
+	plot.Close()
+}
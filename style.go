@@ -0,0 +1,186 @@
+package glot
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Style is a gnuplot plotting style, the way the points of a PointGroup
+// are drawn. The styles used to be plain strings, which are converted
+// with Style(s).
+type Style string
+
+// The gnuplot plotting styles.
+const (
+	StyleLines        Style = "lines"
+	StylePoints       Style = "points"
+	StyleLinesPoints  Style = "linespoints"
+	StyleImpulses     Style = "impulses"
+	StyleDots         Style = "dots"
+	StyleSteps        Style = "steps"
+	StyleFSteps       Style = "fsteps"
+	StyleHiSteps      Style = "histeps"
+	StyleFillSteps    Style = "fillsteps"
+	StyleErrorBars    Style = "errorbars"
+	StyleXErrorBars   Style = "xerrorbars"
+	StyleYErrorBars   Style = "yerrorbars"
+	StyleXYErrorBars  Style = "xyerrorbars"
+	StyleErrorLines   Style = "errorlines"
+	StyleXErrorLines  Style = "xerrorlines"
+	StyleYErrorLines  Style = "yerrorlines"
+	StyleXYErrorLines Style = "xyerrorlines"
+	StyleBoxes        Style = "boxes"
+	StyleBoxErrorBars Style = "boxerrorbars"
+	StyleBoxXYError   Style = "boxxyerror"
+	StyleBoxPlot      Style = "boxplot"
+	StyleCandlesticks Style = "candlesticks"
+	StyleFinanceBars  Style = "financebars"
+	StyleFilledCurves Style = "filledcurves"
+	StyleHistograms   Style = "histograms"
+	StyleCircles      Style = "circles"
+	StyleEllipses     Style = "ellipses"
+	StyleLabels       Style = "labels"
+	StyleVectors      Style = "vectors"
+	StyleParallelAxes Style = "parallelaxes"
+	StyleImage        Style = "image"
+	StyleRGBImage     Style = "rgbimage"
+	StyleRGBAlpha     Style = "rgbalpha"
+	StylePM3D         Style = "pm3d"
+	StyleSurface      Style = "surface"
+	StyleZErrorFill   Style = "zerrorfill"
+	StyleSpiderPlot   Style = "spiderplot"
+	StyleTable        Style = "table"
+)
+
+// gStyles are the styles a PointGroup can be drawn with.
+var gStyles = []Style{
+	StyleLines, StylePoints, StyleLinesPoints, StyleImpulses, StyleDots,
+	StyleSteps, StyleFSteps, StyleHiSteps, StyleFillSteps,
+	StyleErrorBars, StyleXErrorBars, StyleYErrorBars, StyleXYErrorBars,
+	StyleErrorLines, StyleXErrorLines, StyleYErrorLines, StyleXYErrorLines,
+	StyleBoxes, StyleBoxErrorBars, StyleBoxXYError, StyleBoxPlot,
+	StyleCandlesticks, StyleFinanceBars, StyleFilledCurves, StyleHistograms,
+	StyleCircles, StyleEllipses, StyleLabels, StyleVectors, StyleParallelAxes,
+	StyleImage, StyleRGBImage, StyleRGBAlpha, StylePM3D, StyleSurface,
+	StyleZErrorFill, StyleSpiderPlot, StyleTable,
+}
+
+// gStyleAliases maps the style names accepted by earlier versions of glot
+// to the gnuplot style they stand for.
+var gStyleAliases = map[Style]Style{
+	"linepoints": StyleLinesPoints,
+	"lp":         StyleLinesPoints,
+	"bar":        StyleBoxes,
+	"fill solid": StyleFilledCurves,
+	"histogram":  StyleHistograms,
+	"circle":     StyleCircles,
+}
+
+// resolve returns the gnuplot style a Style stands for, and whether it's a
+// known style. Styles are put in plot commands as they are, so no unknown
+// style may reach them.
+func (style Style) resolve() (Style, bool) {
+	if alias, ok := gStyleAliases[style]; ok {
+		return alias, true
+	}
+	for _, s := range gStyles {
+		if s == style {
+			return style, true
+		}
+	}
+	return style, false
+}
+
+// SeriesOptions are the options a PointGroup is drawn with on top of its
+// Style. The zero value of every field leaves the gnuplot default.
+//
+// Usage
+//
+//	plot.AddPointGroup("Latency", glot.StyleLinesPoints, latencies, glot.SeriesOptions{
+//		Color:     "#1f77b4",
+//		LineWidth: 2,
+//		PointType: 7,
+//	})
+type SeriesOptions struct {
+	Color        string  // Color of the lines and points, a name like "red" or "#rrggbb".
+	LineWidth    float64 // Width of the lines, "lw".
+	DashType     int     // Dash pattern of the lines, "dt".
+	PointType    int     // Shape of the points, "pt".
+	PointSize    float64 // Size of the points, "ps".
+	Fill         string  // Fill of the boxes and areas: "empty", "solid", "solid 0.5" or "pattern 2".
+	Transparency float64 // How transparent the fill is, from 0 (opaque) to 1.
+	NoTitle      bool    // Leaves the PointGroup out of the legend.
+	Axes         Axes    // Axes the PointGroup is drawn against, AxesX1Y1 if empty.
+}
+
+// fillStyles matches the fills SeriesOptions accepts, which are put in
+// plot commands as they are.
+var fillStyles = regexp.MustCompile(`^(empty|solid( (0|1|0?\.[0-9]+|1\.0*))?|pattern( [0-9]+)?)$`)
+
+// check returns an error if the options can't be drawn.
+func (opts SeriesOptions) check() error {
+	switch {
+	case opts.LineWidth < 0:
+		return &GnuplotError{Msg: fmt.Sprintf("invalid line width '%v'", opts.LineWidth)}
+	case opts.DashType < 0:
+		return &GnuplotError{Msg: fmt.Sprintf("invalid dash type '%v'", opts.DashType)}
+	case opts.PointType < 0:
+		return &GnuplotError{Msg: fmt.Sprintf("invalid point type '%v'", opts.PointType)}
+	case opts.PointSize < 0:
+		return &GnuplotError{Msg: fmt.Sprintf("invalid point size '%v'", opts.PointSize)}
+	case opts.Fill != "" && !fillStyles.MatchString(opts.Fill):
+		return &GnuplotError{Msg: fmt.Sprintf("invalid fill '%s'", opts.Fill)}
+	case opts.Transparency < 0 || opts.Transparency > 1:
+		return &GnuplotError{Msg: fmt.Sprintf("invalid transparency '%v'", opts.Transparency)}
+	case opts.Axes != "" && !opts.Axes.valid():
+		return &GnuplotError{Msg: fmt.Sprintf("invalid axes '%s'", opts.Axes)}
+	}
+	return nil
+}
+
+// clause returns the part of a plot command which applies the options,
+// with a leading space, or an empty string for the zero value.
+func (opts SeriesOptions) clause() string {
+	var b strings.Builder
+	if opts.Color != "" {
+		fmt.Fprintf(&b, " lc rgb %s", Quote(opts.Color))
+	}
+	if opts.LineWidth > 0 {
+		fmt.Fprintf(&b, " lw %v", opts.LineWidth)
+	}
+	if opts.DashType > 0 {
+		fmt.Fprintf(&b, " dt %d", opts.DashType)
+	}
+	if opts.PointType > 0 {
+		fmt.Fprintf(&b, " pt %d", opts.PointType)
+	}
+	if opts.PointSize > 0 {
+		fmt.Fprintf(&b, " ps %v", opts.PointSize)
+	}
+	fill := opts.Fill
+	if opts.Transparency > 0 {
+		switch {
+		case fill == "" || fill == "solid":
+			fill = fmt.Sprintf("transparent solid %.4g", 1-opts.Transparency)
+		case strings.HasPrefix(fill, "solid ") || strings.HasPrefix(fill, "pattern"):
+			fill = "transparent " + fill
+		}
+	}
+	if fill != "" {
+		fmt.Fprintf(&b, " fs %s", fill)
+	}
+	return b.String()
+}
+
+// seriesOptions returns the single SeriesOptions of a variadic argument,
+// or the zero value if there is none.
+func seriesOptions(opts []SeriesOptions) (SeriesOptions, error) {
+	switch len(opts) {
+	case 0:
+		return SeriesOptions{}, nil
+	case 1:
+		return opts[0], opts[0].check()
+	}
+	return SeriesOptions{}, &GnuplotError{Msg: fmt.Sprintf("expected at most one SeriesOptions, got %d", len(opts))}
+}
@@ -0,0 +1,64 @@
+package glot
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStyleAliases(t *testing.T) {
+	plot, _ := NewPlotWithBackend(1, NewRecorder())
+	for alias, want := range gStyleAliases {
+		if err := plot.AddPointGroup(string(alias), alias, []float64{1, 2}); err != nil {
+			t.Fatal(err)
+		}
+		if pointGroup, _ := plot.PointGroup(string(alias)); pointGroup.Style() != want {
+			t.Errorf("Expected %q to stand for %q, got %q", alias, want, pointGroup.Style())
+		}
+	}
+	if err := plot.ResetPointGroupStyle("lp", "wiggles"); !errors.Is(err, ErrUnknownStyle) {
+		t.Error("Expected ErrUnknownStyle, got ", err)
+	}
+}
+
+func TestSeriesOptions(t *testing.T) {
+	recorder := NewRecorder()
+	plot, _ := NewPlotWithBackend(1, recorder)
+	last := func() string {
+		cmds := recorder.Commands()
+		return cmds[len(cmds)-1]
+	}
+	err := plot.AddPointGroup("A", StyleLinesPoints, []float64{1, 2}, SeriesOptions{
+		Color: "#ff0000", LineWidth: 2, DashType: 2, PointType: 7, PointSize: 1.5,
+		Transparency: 0.25,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `plot $data1 title "A" with linespoints lc rgb "#ff0000" lw 2 dt 2 pt 7 ps 1.5 fs transparent solid 0.75`; last() != want {
+		t.Errorf("Expected %q, got %q", want, last())
+	}
+	plot.ResetPointGroupStyle("A", StyleBoxes)
+	if want := `plot $data1 title "A" with boxes lc rgb "#ff0000" lw 2 dt 2 pt 7 ps 1.5 fs transparent solid 0.75`; last() != want {
+		t.Errorf("Expected the options to be kept, got %q", last())
+	}
+	plot.ResetPointGroupStyle("A", StyleBoxes, SeriesOptions{Fill: "pattern 2", NoTitle: true})
+	if want := `plot $data1 notitle with boxes fs pattern 2`; last() != want {
+		t.Errorf("Expected %q, got %q", want, last())
+	}
+	invalid := []SeriesOptions{
+		{LineWidth: -1},
+		{Fill: "solid; system 'ls'"},
+		{Transparency: 2},
+	}
+	for _, opts := range invalid {
+		if err := plot.AddPointGroup("B", StyleLines, []float64{1, 2}, opts); err == nil {
+			t.Errorf("Expected an error for %+v", opts)
+		}
+	}
+	if err := plot.AddPointGroup("B", StyleLines, []float64{1, 2}, SeriesOptions{}, SeriesOptions{}); err == nil {
+		t.Error("Expected an error for more than one SeriesOptions")
+	}
+	if _, ok := plot.PointGroup("B"); ok {
+		t.Error("Expected no PointGroup to be added with invalid options")
+	}
+}
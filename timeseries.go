@@ -0,0 +1,187 @@
+package glot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimeAxis configures how the x axis of a plot holding time series is
+// labeled.
+type TimeAxis struct {
+	// Layout is how the tick labels are written, in the reference time
+	// layout of the time package, like "15:04" or "Jan 02". Zone elements
+	// and elements which aren't zero-padded aren't supported.
+	// An empty Layout stands for "2006-01-02 15:04".
+	Layout string
+	// Location is the time zone the tick labels are written in, UTC if
+	// nil.
+	Location *time.Location
+}
+
+// gTimeElements maps the elements of a time layout to the gnuplot time
+// format elements writing the same thing, longest elements first.
+var gTimeElements = []struct{ layout, format string }{
+	{"January", "%B"}, {"Jan", "%b"}, {"Monday", "%A"}, {"Mon", "%a"},
+	{"2006", "%Y"}, {"05.000000", "%.6S"}, {"05.000", "%.3S"}, {"002", "%j"},
+	{"01", "%m"}, {"02", "%d"}, {"03", "%I"}, {"04", "%M"}, {"05", "%S"},
+	{"06", "%y"}, {"15", "%H"}, {"PM", "%p"},
+}
+
+// timeFormat converts a time layout to a gnuplot time format.
+func timeFormat(layout string) (string, error) {
+	var b strings.Builder
+	for rest := layout; rest != ""; {
+		matched := false
+		for _, element := range gTimeElements {
+			if strings.HasPrefix(rest, element.layout) {
+				b.WriteString(element.format)
+				rest = rest[len(element.layout):]
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		switch c := rest[0]; {
+		case c >= '0' && c <= '9' || c == '_' || strings.HasPrefix(rest, "MST") || strings.HasPrefix(rest, "Z07") || strings.HasPrefix(rest, "pm"):
+			return "", &GnuplotError{Msg: fmt.Sprintf("unsupported element at %q in time layout %q", rest, layout)}
+		case c == '%':
+			b.WriteString("%%")
+		default:
+			b.WriteByte(c)
+		}
+		rest = rest[1:]
+	}
+	return b.String(), nil
+}
+
+// defaultTimeLayout picks a layout fitting the span of times.
+func defaultTimeLayout(times []time.Time) string {
+	if len(times) == 0 {
+		return "2006-01-02 15:04"
+	}
+	first, last := times[0], times[0]
+	for _, t := range times {
+		if t.Before(first) {
+			first = t
+		}
+		if t.After(last) {
+			last = t
+		}
+	}
+	switch span := last.Sub(first); {
+	case span <= time.Minute:
+		return "15:04:05"
+	case span <= 24*time.Hour:
+		return "15:04"
+	case span <= 7*24*time.Hour:
+		return "Jan 02 15:04"
+	case span <= 366*24*time.Hour:
+		return "Jan 02"
+	}
+	return "2006-01"
+}
+
+// SetTimeAxis configures the x axis of a plot holding time series. It's
+// called by AddTimeSeries with a layout fitting the times of the series if
+// it wasn't called before.
+//
+// Usage
+//
+//	plot, _ := glot.NewPlot(2, false, false)
+//	paris, _ := time.LoadLocation("Europe/Paris")
+//	plot.SetTimeAxis(glot.TimeAxis{Layout: "Jan 02 15:04", Location: paris})
+//	glot.AddTimeSeries(plot, "Requests", glot.StyleLines, times, requests)
+func (plot *Plot) SetTimeAxis(axis TimeAxis) error {
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	return plot.setTimeAxis(axis)
+}
+
+func (plot *Plot) setTimeAxis(axis TimeAxis) error {
+	if axis.Layout == "" {
+		axis.Layout = "2006-01-02 15:04"
+	}
+	if axis.Location == nil {
+		axis.Location = time.UTC
+	}
+	format, err := timeFormat(axis.Layout)
+	if err != nil {
+		return err
+	}
+	for _, s := range []struct{ key, cmd string }{
+		{"xdata", "set xdata time"},
+		{"timefmt", "set timefmt " + Quote("%s")},
+		{"format x", "set format x " + Quote(format)},
+	} {
+		if err := plot.applySetting(s.key, s.cmd); err != nil {
+			return err
+		}
+	}
+	moved := plot.timeAxis != nil && plot.timeAxis.Location != axis.Location
+	plot.timeAxis = &axis
+	if !moved {
+		return nil
+	}
+	// The times are sent shifted to the time zone of the labels, so the
+	// time series have to be sent again.
+	for _, pointGroup := range plot.pointGroups {
+		if pointGroup.times != nil {
+			if err := plot.releaseData(pointGroup); err != nil {
+				return err
+			}
+			columns := pointGroup.columns()
+			pointGroup.castedData = [][]float64{plot.timeColumn(pointGroup.times), columns[1]}
+		}
+	}
+	if plot.nplots == 0 {
+		return nil
+	}
+	return plot.redraw()
+}
+
+// timeColumn converts times to the seconds gnuplot reads with the "%s"
+// time format. gnuplot writes times as UTC, so they are shifted by the
+// offset of the time zone of the labels.
+func (plot *Plot) timeColumn(times []time.Time) []float64 {
+	column := make([]float64, len(times))
+	for i, t := range times {
+		_, offset := t.In(plot.timeAxis.Location).Zone()
+		column[i] = float64(t.Unix()+int64(offset)) + float64(t.Nanosecond())/1e9
+	}
+	return column
+}
+
+// AddTimeSeries adds a PointGroup drawing y against the times t to a 2-d
+// plot, whose x axis is switched to time.
+//
+// Usage
+//
+//	plot, _ := glot.NewPlot(2, false, false)
+//	start := time.Now()
+//	times := []time.Time{start, start.Add(time.Minute), start.Add(2 * time.Minute)}
+//	glot.AddTimeSeries(plot, "Latency", glot.StyleLines, times, []float64{12.5, 14, 11})
+//	plot.SavePlot("latency.png")
+func AddTimeSeries[Y Number](plot *Plot, name string, style Style, t []time.Time, y []Y, opts ...SeriesOptions) error {
+	if len(t) != len(y) {
+		return &GnuplotError{Kind: ErrDimensionMismatch, Msg: fmt.Sprintf("t has %d values but y has %d", len(t), len(y))}
+	}
+	plot.mu.Lock()
+	defer plot.mu.Unlock()
+	if plot.dimensions != 2 {
+		return &GnuplotError{Kind: ErrDimensionMismatch, Msg: fmt.Sprintf("time series can only be added to 2-d plots, this plot has %v dimensions", plot.dimensions)}
+	}
+	if _, exists := plot.pointGroups[name]; exists {
+		return &GnuplotError{Kind: ErrDuplicateGroup, Msg: fmt.Sprintf("A PointGroup with the name %s  already exists, please use another name of the curve or remove this curve before using another one with the same name.", name)}
+	}
+	if plot.timeAxis == nil {
+		if err := plot.setTimeAxis(TimeAxis{Layout: defaultTimeLayout(t)}); err != nil {
+			return err
+		}
+	}
+	times := append([]time.Time(nil), t...)
+	columns := [][]float64{plot.timeColumn(times), toFloat64(y)}
+	return plot.addPointGroup(name, style, times, columns, true, opts)
+}
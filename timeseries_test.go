@@ -0,0 +1,94 @@
+package glot
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTimeFormat(t *testing.T) {
+	tests := map[string]string{
+		"15:04":                   "%H:%M",
+		"Jan 02":                  "%b %d",
+		"2006-01-02 15:04:05.000": "%Y-%m-%d %H:%M:%.3S",
+		"Monday 03PM (%)":         "%A %I%p (%%)",
+	}
+	for layout, want := range tests {
+		if got, err := timeFormat(layout); err != nil || got != want {
+			t.Errorf("Expected %q to convert to %q, got %q, %v", layout, want, got, err)
+		}
+	}
+	for _, layout := range []string{"3:04", "15:04 MST", "Jan _2", "2006-01-02T15:04:05Z07:00"} {
+		if _, err := timeFormat(layout); err == nil {
+			t.Errorf("Expected an error for the layout %q", layout)
+		}
+	}
+}
+
+func TestAddTimeSeries(t *testing.T) {
+	recorder := NewRecorder()
+	plot, _ := NewPlotWithBackend(2, recorder)
+	start := time.Date(2024, 3, 1, 12, 0, 0, 500000000, time.UTC)
+	times := []time.Time{start, start.Add(time.Minute), start.Add(time.Hour)}
+	if err := AddTimeSeries(plot, "Latency", StyleLines, times, []int{12, 14, 11}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"set xdata time",
+		`set timefmt "%s"`,
+		`set format x "%H:%M"`,
+		`plot $data1 using 1:2 title "Latency" with lines`,
+	}
+	if got := recorder.Commands(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	x := float64(start.Unix()) + 0.5
+	if data := lastData(recorder); !reflect.DeepEqual(data, [][]float64{{x, x + 60, x + 3600}, {12, 14, 11}}) {
+		t.Errorf("Expected the times in seconds, got %v", data)
+	}
+
+	recorder.Reset()
+	if err := plot.SetTimeAxis(TimeAxis{Layout: "Jan 02 15:04", Location: time.FixedZone("CEST", 2*3600)}); err != nil {
+		t.Fatal(err)
+	}
+	want = []string{
+		"set xdata time",
+		`set timefmt "%s"`,
+		`set format x "%b %d %H:%M"`,
+		`plot $data2 using 1:2 title "Latency" with lines`,
+	}
+	if got := recorder.Commands(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if data := lastData(recorder); data[0][0] != x+7200 {
+		t.Errorf("Expected the times to be shifted to the time zone, got %v", data)
+	}
+
+	if err := AddTimeSeries(plot, "Short", StyleLines, times, []int{1}); !errors.Is(err, ErrDimensionMismatch) {
+		t.Error("Expected ErrDimensionMismatch, got ", err)
+	}
+	if err := AddTimeSeries(plot, "Latency", StyleLines, times, []int{1, 2, 3}); !errors.Is(err, ErrDuplicateGroup) {
+		t.Error("Expected ErrDuplicateGroup, got ", err)
+	}
+	plot1d, _ := NewPlotWithBackend(1, recorder)
+	if err := AddTimeSeries(plot1d, "Latency", StyleLines, times, []int{1, 2, 3}); !errors.Is(err, ErrDimensionMismatch) {
+		t.Error("Expected ErrDimensionMismatch on a 1-d plot, got ", err)
+	}
+}
+
+func TestDefaultTimeLayout(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := map[time.Duration]string{
+		30 * time.Second:     "15:04:05",
+		3 * time.Hour:        "15:04",
+		72 * time.Hour:       "Jan 02 15:04",
+		90 * 24 * time.Hour:  "Jan 02",
+		900 * 24 * time.Hour: "2006-01",
+	}
+	for span, want := range tests {
+		if got := defaultTimeLayout([]time.Time{start.Add(span), start}); got != want {
+			t.Errorf("Expected %q for a span of %v, got %q", want, span, got)
+		}
+	}
+}